@@ -21,7 +21,10 @@ import (
 	"fmt"
 	"os"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"unicode"
 
 	"github.com/gookit/color"
 	"github.com/hashicorp/hcl/v2/hclsimple"
@@ -50,32 +53,196 @@ var (
 
 // public config, set via config file or using defaults
 type Settings struct {
-	FG             string `hcl:"FG"`
-	BG             string `hcl:"BG"`
-	HighlightFG    string `hcl:"HighlightFG"`
-	HighlightBG    string `hcl:"HighlightBG"`
-	NoHighlightFG  string `hcl:"NoHighlightFG"`
-	NoHighlightBG  string `hcl:"NoHighlightBG"`
-	HighlightHdrFG string `hcl:"HighlightHdrFG"`
-	HighlightHdrBG string `hcl:"HighlightHdrBG"`
+	FG             string      `hcl:"FG,optional"`
+	BG             string      `hcl:"BG,optional"`
+	HighlightFG    string      `hcl:"HighlightFG,optional"`
+	HighlightBG    string      `hcl:"HighlightBG,optional"`
+	NoHighlightFG  string      `hcl:"NoHighlightFG,optional"`
+	NoHighlightBG  string      `hcl:"NoHighlightBG,optional"`
+	HighlightHdrFG string      `hcl:"HighlightHdrFG,optional"`
+	HighlightHdrBG string      `hcl:"HighlightHdrBG,optional"`
+	FilterSets     []FilterSet `hcl:"filter,block"`
 }
 
+// A named set of filters defined in the config file, which can be
+// used with --use-filter name
+type FilterSet struct {
+	Name        string   `hcl:"name,label"`
+	Patterns    []string `hcl:"patterns,optional"`
+	Filters     []string `hcl:"filters,optional"`
+	Expressions []string `hcl:"expressions,optional"`
+}
+
+// A transposer given with -R, either a regexp or a function
 type Transposer struct {
-	Search  regexp.Regexp
-	Replace string
+	Search   regexp.Regexp
+	Replace  string
+	Function string // one of TransposerFuncs, used instead of Search
+	Length   int    // parameter of truncate
+	Columns  string // column spec prefix, -T is used if empty
+
+	// only transpose rows matching this filter group: rule if col=value
+	Condition FilterGroup
 }
 
 type Pattern struct {
 	Pattern   string
 	PatternRe *regexp.Regexp
 	Negate    bool
+	Columns   string // column spec the pattern is restricted to, if any
+
+	// the same pattern restricted to columns, if it looks like
+	// cols:/pattern/. It is only used if the columns exist, otherwise
+	// the whole string is the pattern, eg https://x.org/
+	Scope *Pattern
 }
 
+// A field filter given with -F. Filters using = or != match a regex,
+// the others compare the value according to the column type.
 type Filter struct {
+	Field  string
+	Op     string
+	Value  string
 	Regex  *regexp.Regexp
 	Negate bool
 }
 
+// A semi-join filter given with --in col=file[:keycol] or --not-in,
+// the values of Column must (not) appear in the key set read from File
+type KeyFilter struct {
+	Column    string
+	File      string
+	KeyColumn string // if set, File is a table, otherwise a plain list
+	Negate    bool
+}
+
+// A value mapping given with --map col=file[:keycol:valcol], the
+// values of Column are looked up in the dictionary read from File
+type ValueMap struct {
+	Column    string
+	File      string
+	KeyColumn string // if set, File is a table, otherwise a dictionary
+	ValColumn string
+}
+
+// An aggregation given with --agg, eg sum(restarts). Column is empty
+// for count.
+type Aggregation struct {
+	Func   string
+	Column string
+	Header string
+}
+
+// A computed column given with --add 'name = expression'
+type Computed struct {
+	Name       string
+	Expression string
+}
+
+// A column split given with --split col=/regexp/name,..., every
+// capture group becomes a new column. Names may be empty, in which
+// case the names of the capture groups or generated ones are used.
+type Split struct {
+	Column string
+	Search *regexp.Regexp
+	Names  []string
+}
+
+// A merged column given with --merge 'name={col}/{col}'
+type Merge struct {
+	Name     string
+	Template string
+}
+
+// A header rename given with --rename column=name
+type Rename struct {
+	Column string
+	Name   string
+}
+
+// A group of field filters given as one -F separated by |, the group
+// matches if any of its filters matches
+type FilterGroup []Filter
+
+// column types, determined by type inference or set using --types
+const (
+	TypeString = iota
+	TypeInt
+	TypeFloat
+	TypeBool
+	TypeDuration
+	TypeTime
+	TypeSize
+	TypeIP
+)
+
+// maps type names to column types, used by --types and for display
+var TypeNames = map[string]int{
+	"string":    TypeString,
+	"int":       TypeInt,
+	"float":     TypeFloat,
+	"bool":      TypeBool,
+	"duration":  TypeDuration,
+	"time":      TypeTime,
+	"timestamp": TypeTime,
+	"size":      TypeSize,
+	"bytes":     TypeSize,
+	"ip":        TypeIP,
+}
+
+// field filter: field, operator, value. The field name is matched
+// lazily, so that the first operator wins
+var (
+	filterRe = regexp.MustCompile(`^(.+?)(!=|>=|<=|=|>|<)(.*)$`)
+
+	// a member of an OR-group, the field name must look like a header
+	filterGroupRe = regexp.MustCompile(`^[\w.()-]+(!=|>=|<=|=|>|<)`)
+)
+
+// valid aggregate functions, see --agg
+var AggregateFuncs = []string{"count", "sum", "avg", "min", "max"}
+
+var aggRe = regexp.MustCompile(`^(\w+)(?:\((.+)\))?$`)
+
+// computed column: name = expression
+var computedRe = regexp.MustCompile(`^\s*([\w.-]+)\s*=\s*(.+)$`)
+
+// functions usable as transposers, see -R
+var TransposerFuncs = []string{"upper", "lower", "trim", "truncate(n)"}
+
+var transposerFuncRe = regexp.MustCompile(`^(upper|lower|trim|truncate)(?:\((\d+)\))?$`)
+
+// valid named header transformers, see --header-transform
+var HeaderTransforms = []string{"lower", "upper", "snake"}
+
+// valid join types, see --join-type
+var JoinTypes = []string{"inner", "left", "full"}
+
+// valid policies for malformed input rows, see --on-error
+var ErrorPolicies = []string{"fail", "skip", "pad", "merge"}
+
+// return the canonical name of a column type
+func TypeName(kind int) string {
+	switch kind {
+	case TypeInt:
+		return "int"
+	case TypeFloat:
+		return "float"
+	case TypeBool:
+		return "bool"
+	case TypeDuration:
+		return "duration"
+	case TypeTime:
+		return "time"
+	case TypeSize:
+		return "size"
+	case TypeIP:
+		return "ip"
+	default:
+		return "string"
+	}
+}
+
 // internal config
 type Config struct {
 	Debug          bool
@@ -90,6 +257,7 @@ type Config struct {
 	InvertMatch    bool
 	Patterns       []*Pattern
 	UseFuzzySearch bool
+	FuzzyRank      bool // sort by fuzzy rank, --fuzzy-rank
 	UseHighlight   bool
 	Interactive    bool
 	InputJSON      bool
@@ -101,6 +269,46 @@ type Config struct {
 	SortByColumn    string // 1,2
 	UseSortByColumn []int  // []int{1,2}
 
+	// deduplicate rows by key columns: --unique, --unique-last, --unique-count
+	Unique           string
+	UseUniqueColumns []int
+	UniqueLast       bool
+	UniqueCount      bool
+
+	// group rows and aggregate: --group-by status --agg count,sum(restarts)
+	GroupBy      string
+	RawAgg       string
+	Aggregations []Aggregation
+
+	// pivot: --pivot rowcolumns:column --pivot-agg sum(x) --pivot-fill 0
+	Pivot       string
+	PivotRows   string
+	PivotColumn string
+	RawPivotAgg string
+	PivotAgg    Aggregation
+	PivotFill   string
+
+	// footer row: --footer sum(restarts),count(name)
+	RawFooter string
+	Footer    []Aggregation
+
+	// reverse pivot, all columns except these become key/value rows
+	Unpivot string
+
+	// print per column statistics instead of the table, --stats
+	Stats    bool
+	StatsTop int
+
+	// transpose the whole table before printing, --rotate
+	Rotate bool
+
+	// limit output rows after sorting: --offset, --head, --tail, --sample
+	Offset int
+	Head   int
+	Tail   int
+	Sample int
+	Seed   uint64 // random seed for --sample, 0 => random
+
 	TransposeColumns    string       // 1,2
 	UseTransposeColumns []int        // []int{1,2}
 	Transposers         []string     // []string{"/ /-/", "/foo/bar/"}
@@ -122,13 +330,80 @@ type Config struct {
 
 	Settings Settings
 
-	// used for field filtering
+	// used for field filtering, all groups must match
 	Rawfilters []string
-	Filters    map[string]Filter //map[string]*regexp.Regexp
+	Filters    []FilterGroup
+
+	// named filter sets from the config file, --use-filter name
+	UseFilters  []string
+	SetPatterns []string
+
+	// positional patterns are OR-ed instead of AND-ed, --any
+	AnyPattern bool
+
+	// restrict positional patterns to these columns, --pattern-columns
+	PatternColumns string
+
+	// rows to show around pattern matches, --context etc
+	Context       int
+	BeforeContext int
+	AfterContext  int
+	ContextStyle  color.Style
+
+	// semi-join filters, --in col=file, --not-in col=file
+	RawIn      []string
+	RawNotIn   []string
+	KeyFilters []KeyFilter
+
+	// value mappings, --map col=file, --map-add, --map-default value
+	RawMaps    []string
+	Maps       []ValueMap
+	MapAdd     bool   // add a new column instead of replacing values
+	MapDefault string // used for values not found in the dictionary
+
+	// join with another table: --join file --on left[=right]
+	Join       string
+	JoinOn     string
+	JoinType   string // inner, left or full
+	JoinPrefix string // prefix for colliding right headers
+
+	// expression filters, -E 'restarts > 5 && age < 1h'
+	Expressions []string
+
+	// computed columns, --add 'age_s = seconds(age)'
+	RawComputed []string
+	Computed    []Computed
+
+	// split columns by regexp capture groups, --split col=/re/names
+	RawSplits []string
+	Splits    []Split
+	SplitKeep bool // keep the source column
+
+	// copy the last non-empty value downwards: --fill-down col,...
+	FillDown string
+
+	// rename headers: --rename col=name, --header-transform snake
+	RawRenames      []string
+	Renames         []Rename
+	HeaderTransform string
+	HeaderRegex     *Transposer // used if HeaderTransform is /search/replace/
+
+	// merge columns using a template, --merge 'ref={namespace}/{name}'
+	RawMerges   []string
+	Merges      []Merge
+	MergeRemove bool // remove the source columns
+
+	// type overrides, --types col=kind
+	Rawtypes []string
+	Types    map[string]int // column spec => cfg.Type*
 
 	// -r <file>
 	InputFile string
 
+	// what to do with malformed input rows: fail, skip, pad or merge
+	OnError string
+	Lenient bool
+
 	OFS string
 }
 
@@ -161,6 +436,7 @@ type Sortmode struct {
 	Numeric bool
 	Time    bool
 	Age     bool
+	String  bool
 }
 
 // default color schemes
@@ -245,6 +521,7 @@ func (conf *Config) DetermineColormode() {
 		conf.HighlightStyle = color.New(colors[level]["hlbg"], colors[level]["hlfg"])
 		conf.NoHighlightStyle = color.New(colors[level]["nohlbg"], colors[level]["nohlfg"])
 		conf.HighlightHdrStyle = color.New(colors[level]["hdrbg"], colors[level]["hdrfg"])
+		conf.ContextStyle = color.New(color.OpFuzzy)
 	}
 }
 
@@ -273,8 +550,11 @@ func (conf *Config) PrepareSortFlags(flag Sortmode) {
 		conf.SortMode = "duration"
 	case flag.Time:
 		conf.SortMode = "time"
-	default:
+	case flag.String:
 		conf.SortMode = "string"
+	default:
+		// use the column type determined by type inference
+		conf.SortMode = "auto"
 	}
 }
 
@@ -300,53 +580,569 @@ func (conf *Config) PrepareModeFlags(flag Modeflag) {
 }
 
 func (conf *Config) PrepareFilters() error {
-	conf.Filters = make(map[string]Filter, len(conf.Rawfilters))
+	if err := conf.PrepareFilterSets(); err != nil {
+		return err
+	}
+
+	conf.Filters = make([]FilterGroup, 0, len(conf.Rawfilters))
 
 	for _, rawfilter := range conf.Rawfilters {
-		filter := Filter{}
+		group := FilterGroup{}
 
-		parts := strings.Split(rawfilter, "!=")
-		if len(parts) != MAXPARTS {
-			parts = strings.Split(rawfilter, "=")
+		for _, rawpart := range splitFilterGroup(rawfilter) {
+			filter, err := parseFilter(rawpart)
+			if err != nil {
+				return err
+			}
+
+			group = append(group, filter)
+		}
 
-			if len(parts) != MAXPARTS {
-				return errors.New("filter field and value must be separated by '=' or '!='")
+		conf.Filters = append(conf.Filters, group)
+	}
+
+	return nil
+}
+
+/*
+Parse --in and --not-in filters: col=file or col=file:keycol. The
+keycol suffix is only split off if file:keycol itself is not an
+existing file.
+*/
+func (conf *Config) PrepareKeyFilters() error {
+	conf.KeyFilters = []KeyFilter{}
+
+	for idx, raw := range [][]string{conf.RawIn, conf.RawNotIn} {
+		negate := idx == 1 // --not-in
+
+		for _, rawfilter := range raw {
+			column, file, found := strings.Cut(rawfilter, "=")
+			if !found || column == "" || file == "" {
+				return fmt.Errorf("key filter %s must have the format column=file[:keycolumn]", rawfilter)
+			}
+
+			filter := KeyFilter{Column: column, File: file, Negate: negate}
+
+			if _, err := os.Stat(file); err != nil {
+				if idx := strings.LastIndex(file, ":"); idx > 0 && idx < len(file)-1 {
+					filter.File = file[:idx]
+					filter.KeyColumn = file[idx+1:]
+				}
 			}
-		} else {
-			filter.Negate = true
+
+			conf.KeyFilters = append(conf.KeyFilters, filter)
+		}
+	}
+
+	return nil
+}
+
+// parse the computed columns given with --add name=expression
+func (conf *Config) PrepareComputed() error {
+	conf.Computed = []Computed{}
+
+	for _, raw := range conf.RawComputed {
+		parts := computedRe.FindStringSubmatch(raw)
+		if len(parts) != 3 || strings.HasPrefix(parts[2], "=") {
+			return fmt.Errorf("computed column %s must have the format name=expression", raw)
+		}
+
+		conf.Computed = append(conf.Computed, Computed{Name: parts[1], Expression: parts[2]})
+	}
+
+	return nil
+}
+
+/*
+Parse the splits given with --split col=/regexp/name,... Like with -R
+the first character after the = is used as delimiter, so any other
+character can be used if the regexp contains slashes.
+*/
+func (conf *Config) PrepareSplits() error {
+	conf.Splits = []Split{}
+
+	for _, raw := range conf.RawSplits {
+		column, rest, found := strings.Cut(raw, "=")
+		if !found || column == "" || len(rest) < 2 {
+			return fmt.Errorf("split %s must have the format column=/regexp/[name,...]", raw)
 		}
 
-		reg, err := regexp.Compile(parts[1])
+		delim := rest[:1]
+
+		end := strings.LastIndex(rest, delim)
+		if end == 0 {
+			return fmt.Errorf("split %s must have the format column=/regexp/[name,...]", raw)
+		}
+
+		search, err := regexp.Compile(rest[1:end])
 		if err != nil {
-			return fmt.Errorf("failed to compile filter regex for field %s: %w",
-				parts[0], err)
+			return fmt.Errorf("invalid split regexp %s: %w", rest[1:end], err)
+		}
+
+		if search.NumSubexp() == 0 {
+			return fmt.Errorf("split regexp %s must contain at least one capture group", rest[1:end])
+		}
+
+		split := Split{Column: column, Search: search}
+
+		if names := rest[end+1:]; names != "" {
+			split.Names = strings.Split(names, ",")
+		}
+
+		if len(split.Names) > search.NumSubexp() {
+			return fmt.Errorf("split %s has more names than capture groups", raw)
+		}
+
+		conf.Splits = append(conf.Splits, split)
+	}
+
+	return nil
+}
+
+/*
+Parse the header renames given  with --rename col=name and the header
+transformer  given  with  --header-transform,  which  is  one  of  the
+HeaderTransforms or a /search/replace/ regexp.
+*/
+func (conf *Config) PrepareRenames() error {
+	conf.Renames = []Rename{}
+
+	for _, raw := range conf.RawRenames {
+		column, name, found := strings.Cut(raw, "=")
+		if !found || column == "" || name == "" {
+			return fmt.Errorf("rename %s must have the format column=name", raw)
+		}
+
+		conf.Renames = append(conf.Renames, Rename{Column: column, Name: name})
+	}
+
+	transform := conf.HeaderTransform
+	if transform == "" || slices.Contains(HeaderTransforms, transform) {
+		return nil
+	}
+
+	parts := strings.Split(transform, transform[:1])
+	if len(parts) != 4 || unicode.IsLetter(rune(transform[0])) {
+		return fmt.Errorf("header transformer must be one of %s or /regexp/replace-string/",
+			strings.Join(HeaderTransforms, "|"))
+	}
+
+	search, err := regexp.Compile(parts[1])
+	if err != nil {
+		return fmt.Errorf("invalid header transformer regexp %s: %w", parts[1], err)
+	}
+
+	conf.HeaderRegex = &Transposer{Search: *search, Replace: parts[2]}
+
+	return nil
+}
+
+// parse the merges given with --merge name=template
+func (conf *Config) PrepareMerges() error {
+	conf.Merges = []Merge{}
+
+	for _, raw := range conf.RawMerges {
+		name, template, found := strings.Cut(raw, "=")
+		if !found || name == "" || !strings.Contains(template, "{") {
+			return fmt.Errorf("merge %s must have the format name=template, eg ref={namespace}/{name}", raw)
+		}
+
+		conf.Merges = append(conf.Merges, Merge{Name: strings.TrimSpace(name), Template: strings.TrimLeft(template, " ")})
+	}
+
+	return nil
+}
+
+/*
+Parse the value mappings given with --map col=file[:keycol:valcol].
+Like with key filters, the columns are only split off if the file
+doesn't exist.
+*/
+func (conf *Config) PrepareMaps() error {
+	conf.Maps = []ValueMap{}
+
+	for _, rawmap := range conf.RawMaps {
+		column, file, found := strings.Cut(rawmap, "=")
+		if !found || column == "" || file == "" {
+			return fmt.Errorf("mapping %s must have the format column=file[:keycolumn:valuecolumn]", rawmap)
+		}
+
+		mapping := ValueMap{Column: column, File: file}
+
+		if _, err := os.Stat(file); err != nil {
+			if parts := strings.Split(file, ":"); len(parts) >= 3 {
+				mapping.File = strings.Join(parts[:len(parts)-2], ":")
+				mapping.KeyColumn = parts[len(parts)-2]
+				mapping.ValColumn = parts[len(parts)-1]
+
+				if mapping.KeyColumn == "" || mapping.ValColumn == "" {
+					return fmt.Errorf("mapping %s must have the format column=file[:keycolumn:valuecolumn]", rawmap)
+				}
+			}
+		}
+
+		conf.Maps = append(conf.Maps, mapping)
+	}
+
+	return nil
+}
+
+// add the filters of  all filter sets given with  --use-filter to the
+// ones specified on the commandline
+func (conf *Config) PrepareFilterSets() error {
+	for _, name := range conf.UseFilters {
+		idx := slices.IndexFunc(conf.Settings.FilterSets, func(set FilterSet) bool {
+			return set.Name == name
+		})
+
+		if idx < 0 {
+			return fmt.Errorf("filter set %s is not defined in config file", name)
+		}
+
+		set := conf.Settings.FilterSets[idx]
+
+		conf.Rawfilters = append(conf.Rawfilters, set.Filters...)
+		conf.Expressions = append(conf.Expressions, set.Expressions...)
+		conf.SetPatterns = append(conf.SetPatterns, set.Patterns...)
+	}
+
+	return nil
+}
+
+/*
+Split a filter into an OR-group, eg status=Error|restarts>5. We only
+split if every part is a filter on its own, so that regexps like
+name=foo|bar continue to work.
+*/
+func splitFilterGroup(rawfilter string) []string {
+	parts := strings.Split(rawfilter, "|")
+	if len(parts) == 1 {
+		return parts
+	}
+
+	for _, part := range parts {
+		if !filterGroupRe.MatchString(part) {
+			return []string{rawfilter}
+		}
+	}
+
+	return parts
+}
+
+func parseFilter(rawfilter string) (Filter, error) {
+	parts := filterRe.FindStringSubmatch(rawfilter)
+	if len(parts) != 4 {
+		return Filter{}, errors.New("filter field and value must be separated by one of: = != < <= > >=")
+	}
+
+	filter := Filter{
+		Field:  strings.ToLower(parts[1]),
+		Op:     parts[2],
+		Value:  parts[3],
+		Negate: parts[2] == "!=",
+	}
+
+	if filter.Op == "=" || filter.Op == "!=" {
+		reg, err := regexp.Compile(filter.Value)
+		if err != nil {
+			return Filter{}, fmt.Errorf("failed to compile filter regex for field %s: %w",
+				filter.Field, err)
 		}
 
 		filter.Regex = reg
-		conf.Filters[strings.ToLower(parts[0])] = filter
+	}
+
+	return filter, nil
+}
+
+// parse type overrides  given with --types, which  may be specified
+// multiple times or as a comma separated list: col=kind,col=kind
+func (conf *Config) PrepareTypes() error {
+	conf.Types = make(map[string]int, len(conf.Rawtypes))
+
+	for _, rawtypes := range conf.Rawtypes {
+		for _, rawtype := range strings.Split(rawtypes, ",") {
+			parts := strings.Split(rawtype, "=")
+			if len(parts) != MAXPARTS || len(parts[0]) == 0 {
+				return fmt.Errorf("type override %s must have the format column=type", rawtype)
+			}
+
+			kind, ok := TypeNames[strings.ToLower(parts[1])]
+			if !ok {
+				return fmt.Errorf("unknown column type %s for column %s", parts[1], parts[0])
+			}
+
+			conf.Types[parts[0]] = kind
+		}
 	}
 
 	return nil
 }
 
-// check if transposers match transposer columns and prepare transposer structs
+/*
+Parse the transposers given with -R into transposer structs. A
+transposer is either a /search/replace/ regexp, where the delimiter
+may be any non alphanumeric character, or one of the
+TransposerFuncs. It may be prefixed with a column spec followed by a
+colon, eg name:upper, in which case it applies to the matching columns
+instead of the ones given with -T. Columns are resolved later by
+lib.PrepareTransposerColumns().
+
+A transposer may be followed by " if " and a field filter as used with
+-F, eg age:/^.+$/stale/ if status=Completed, in which case it is only
+applied to matching rows.
+*/
 func (conf *Config) PrepareTransposers() error {
-	if len(conf.Transposers) != len(conf.UseTransposeColumns) {
-		return fmt.Errorf("the number of transposers needs to correspond to the number of transpose columns: %d != %d",
-			len(conf.Transposers), len(conf.UseTransposeColumns))
+	conf.UseTransposers = []Transposer{}
+
+	for _, raw := range conf.Transposers {
+		transposer, err := parseTransposer(raw)
+		if err != nil {
+			return err
+		}
+
+		conf.UseTransposers = append(conf.UseTransposers, transposer)
+	}
+
+	return nil
+}
+
+/*
+Parse a transposer with an optional condition: rule if field=value. The
+rule itself may contain " if ", so the text after it is only treated as
+condition if the part before it is a complete transposer.
+*/
+func parseTransposer(raw string) (Transposer, error) {
+	for offset := 0; ; {
+		idx := strings.Index(raw[offset:], " if ")
+		if idx < 0 {
+			break
+		}
+
+		idx += offset
+		offset = idx + 1
+
+		transposer, err := parseTransposerSpec(raw[:idx])
+		if err != nil {
+			continue
+		}
+
+		for _, rawfilter := range splitFilterGroup(strings.TrimSpace(raw[idx+4:])) {
+			filter, err := parseFilter(rawfilter)
+			if err != nil {
+				return transposer, fmt.Errorf("invalid transposer condition %s: %w", rawfilter, err)
+			}
+
+			transposer.Condition = append(transposer.Condition, filter)
+		}
+
+		return transposer, nil
+	}
+
+	return parseTransposerSpec(raw)
+}
+
+// parse a transposer without condition: [column:]rule
+func parseTransposerSpec(raw string) (Transposer, error) {
+	if transposer, ok, err := parseTransposerRule(raw); ok {
+		return transposer, err
+	}
+
+	// column spec prefix: col:rule
+	if columns, rule, found := strings.Cut(raw, ":"); found && columns != "" {
+		transposer, ok, err := parseTransposerRule(rule)
+		if ok {
+			transposer.Columns = columns
+
+			return transposer, err
+		}
+	}
+
+	return Transposer{}, fmt.Errorf("transposer %s must have the format [column:]/regexp/replace-string/ or [column:]%s",
+		raw, strings.Join(TransposerFuncs, "|"))
+}
+
+// parse a transposer rule without column prefix, returns false if it
+// doesn't look like one
+func parseTransposerRule(rule string) (Transposer, bool, error) {
+	if match := transposerFuncRe.FindStringSubmatch(rule); match != nil {
+		transposer := Transposer{Function: match[1]}
+
+		switch {
+		case match[1] == "truncate" && match[2] == "":
+			return transposer, true, errors.New("transposer function truncate requires a length, eg truncate(10)")
+		case match[1] != "truncate" && match[2] != "":
+			return transposer, true, fmt.Errorf("transposer function %s doesn't take a parameter", match[1])
+		case match[2] != "":
+			transposer.Length, _ = strconv.Atoi(match[2])
+		}
+
+		return transposer, true, nil
+	}
+
+	if rule == "" || unicode.IsLetter(rune(rule[0])) || unicode.IsDigit(rune(rule[0])) {
+		return Transposer{}, false, nil
+	}
+
+	parts := strings.Split(rule, rule[:1])
+	if len(parts) != 4 || parts[3] != "" {
+		return Transposer{}, false, nil
+	}
+
+	search, err := regexp.Compile(parts[1])
+	if err != nil {
+		return Transposer{}, true, fmt.Errorf("invalid transposer regexp %s: %w", parts[1], err)
+	}
+
+	return Transposer{Search: *search, Replace: parts[2]}, true, nil
+}
+
+/*
+Parse the aggregations given with --agg, eg count,sum(restarts). If
+--group-by has been given without --agg, the rows are counted.
+*/
+func (conf *Config) PrepareAggregations() error {
+	conf.Aggregations = []Aggregation{}
+
+	rawagg := conf.RawAgg
+	if rawagg == "" {
+		if conf.GroupBy == "" {
+			return nil
+		}
+
+		rawagg = "count"
+	}
+
+	aggregations, err := parseAggregations(rawagg)
+	if err != nil {
+		return err
 	}
 
-	for _, transposer := range conf.Transposers {
-		parts := strings.Split(transposer, string(transposer[0]))
-		if len(parts) != 4 {
-			return fmt.Errorf("transposer function must have the format /regexp/replace-string/")
+	conf.Aggregations = aggregations
+
+	return nil
+}
+
+func parseAggregations(rawagg string) ([]Aggregation, error) {
+	aggregations := []Aggregation{}
+
+	for _, rawfunc := range strings.Split(rawagg, ",") {
+		rawfunc = strings.TrimSpace(rawfunc)
+
+		parts := aggRe.FindStringSubmatch(rawfunc)
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("aggregation %s must have the format function(column)", rawfunc)
+		}
+
+		agg := Aggregation{Func: strings.ToLower(parts[1]), Column: parts[2], Header: parts[1]}
+
+		if !slices.Contains(AggregateFuncs, agg.Func) {
+			return nil, fmt.Errorf("unknown aggregate function %s, valid ones: %s",
+				parts[1], strings.Join(AggregateFuncs, "|"))
+		}
+
+		if agg.Column != "" {
+			agg.Header += "_" + agg.Column
+		} else if agg.Func != "count" {
+			return nil, fmt.Errorf("aggregate function %s requires a column", agg.Func)
 		}
 
-		conf.UseTransposers = append(conf.UseTransposers,
-			Transposer{
-				Search:  *regexp.MustCompile(parts[1]),
-				Replace: parts[2]},
-		)
+		aggregations = append(aggregations, agg)
+	}
+
+	return aggregations, nil
+}
+
+// parse the aggregations of the footer row given with --footer
+func (conf *Config) PrepareFooter() error {
+	conf.Footer = []Aggregation{}
+
+	if conf.RawFooter == "" {
+		return nil
+	}
+
+	aggregations, err := parseAggregations(conf.RawFooter)
+	if err != nil {
+		return err
+	}
+
+	conf.Footer = aggregations
+
+	return nil
+}
+
+/*
+Parse --pivot rowcolumns:column and the aggregation used for the
+cells given with --pivot-agg, which defaults to count.
+*/
+func (conf *Config) PreparePivot() error {
+	if conf.Pivot == "" {
+		return nil
+	}
+
+	rows, columns, found := strings.Cut(conf.Pivot, ":")
+	if !found || rows == "" || columns == "" {
+		return fmt.Errorf("pivot %s must have the format rowcolumns:column", conf.Pivot)
+	}
+
+	conf.PivotRows = rows
+	conf.PivotColumn = columns
+
+	rawagg := conf.RawPivotAgg
+	if rawagg == "" {
+		rawagg = "count"
+	}
+
+	aggregations, err := parseAggregations(rawagg)
+	if err != nil {
+		return err
+	}
+
+	if len(aggregations) != 1 {
+		return errors.New("--pivot-agg requires exactly one aggregation")
+	}
+
+	conf.PivotAgg = aggregations[0]
+
+	return nil
+}
+
+// check the join options
+func (conf *Config) PrepareJoin() error {
+	if conf.Join == "" {
+		return nil
+	}
+
+	if conf.JoinOn == "" {
+		return errors.New("--join requires the key columns given with --on")
+	}
+
+	if conf.JoinType == "" {
+		conf.JoinType = "inner"
+	}
+
+	if !slices.Contains(JoinTypes, conf.JoinType) {
+		return fmt.Errorf("invalid join type %s, valid ones: %s",
+			conf.JoinType, strings.Join(JoinTypes, "|"))
+	}
+
+	return nil
+}
+
+// check the policy for malformed input rows, --lenient is a shortcut
+// for --on-error=skip
+func (conf *Config) PrepareOnError() error {
+	if conf.Lenient {
+		conf.OnError = "skip"
+	}
+
+	if conf.OnError == "" {
+		conf.OnError = "fail"
+	}
+
+	if !slices.Contains(ErrorPolicies, conf.OnError) {
+		return fmt.Errorf("invalid error policy %s, valid ones: %s",
+			conf.OnError, strings.Join(ErrorPolicies, "|"))
 	}
 
 	return nil
@@ -376,6 +1172,21 @@ func (conf *Config) ApplyDefaults() {
 		conf.Numbering = false
 	}
 
+	if conf.FuzzyRank {
+		conf.UseFuzzySearch = true
+	}
+
+	if conf.Context > 0 {
+		// --before-context and --after-context take precedence
+		if conf.BeforeContext == 0 {
+			conf.BeforeContext = conf.Context
+		}
+
+		if conf.AfterContext == 0 {
+			conf.AfterContext = conf.Context
+		}
+	}
+
 	if conf.Separator[0] == ':' && conf.Separator[len(conf.Separator)-1] == ':' {
 		separator, ok := SeparatorTemplates[conf.Separator]
 		if ok {
@@ -388,30 +1199,31 @@ func (conf *Config) PreparePattern(patterns []*Pattern) error {
 	// regex checks if a pattern looks like /$pattern/[i!]
 	flagre := regexp.MustCompile(`^/(.*)/([i!]*)$`)
 
+	// regex checks if a pattern is restricted to columns: cols:/$pattern/[i!]
+	scopere := regexp.MustCompile(`^([\w.,()|*-]+):(/.*/[i!]*)$`)
+
 	for _, pattern := range patterns {
-		matches := flagre.FindAllStringSubmatch(pattern.Pattern, -1)
-
-		// we have a regex with flags
-		for _, match := range matches {
-			pattern.Pattern = match[1] // the inner part is our actual pattern
-			flags := match[2]          // the flags
-
-			for _, flag := range flags {
-				switch flag {
-				case 'i':
-					pattern.Pattern = `(?i)` + pattern.Pattern
-				case '!':
-					pattern.Negate = true
-				}
-			}
+		if pattern.Columns == "" {
+			pattern.Columns = conf.PatternColumns
 		}
 
-		PatternRe, err := regexp.Compile(pattern.Pattern)
-		if err != nil {
-			return fmt.Errorf("regexp pattern %s is invalid: %w", pattern.Pattern, err)
+		scope := scopere.FindStringSubmatch(pattern.Pattern)
+		if len(scope) == 3 {
+			pattern.Scope = &Pattern{Pattern: scope[2], Columns: scope[1]}
+
+			if err := compilePattern(flagre, pattern.Scope); err != nil {
+				return err
+			}
 		}
 
-		pattern.PatternRe = PatternRe
+		if err := compilePattern(flagre, pattern); err != nil {
+			if pattern.Scope == nil {
+				return err
+			}
+
+			// only usable with its scope
+			pattern.PatternRe = nil
+		}
 	}
 
 	conf.Patterns = patterns
@@ -419,12 +1231,66 @@ func (conf *Config) PreparePattern(patterns []*Pattern) error {
 	return nil
 }
 
+// strip the slashes and flags of a pattern and compile it
+func compilePattern(flagre *regexp.Regexp, pattern *Pattern) error {
+	matches := flagre.FindAllStringSubmatch(pattern.Pattern, -1)
+
+	// we have a regex with flags
+	for _, match := range matches {
+		pattern.Pattern = match[1] // the inner part is our actual pattern
+		flags := match[2]          // the flags
+
+		for _, flag := range flags {
+			switch flag {
+			case 'i':
+				pattern.Pattern = `(?i)` + pattern.Pattern
+			case '!':
+				pattern.Negate = true
+			}
+		}
+	}
+
+	PatternRe, err := regexp.Compile(pattern.Pattern)
+	if err != nil {
+		return fmt.Errorf("regexp pattern %s is invalid: %w", pattern.Pattern, err)
+	}
+
+	pattern.PatternRe = PatternRe
+
+	return nil
+}
+
 func (conf *Config) PrepareCustomHeaders(custom string) {
 	if len(custom) > 0 {
 		conf.CustomHeaders = strings.Split(custom, ",")
 	}
 }
 
+// check if any of the patterns is restricted to columns
+func (conf *Config) ScopedPatterns() bool {
+	return slices.ContainsFunc(conf.Patterns, func(pattern *Pattern) bool {
+		return pattern.Columns != "" || pattern.Scope != nil
+	})
+}
+
+// check if context rows around pattern matches have been requested
+func (conf *Config) UseContext() bool {
+	return len(conf.Patterns) > 0 && (conf.BeforeContext > 0 || conf.AfterContext > 0)
+}
+
+// check if -v has to be applied to the combination of patterns, field
+// filters and expressions, which is only possible on parsed rows
+func (conf *Config) InvertRows() bool {
+	return conf.InvertMatch && (len(conf.Filters) > 0 || len(conf.Expressions) > 0)
+}
+
+// check if patterns must be matched against parsed rows instead of
+// raw input lines, which is also the case with --fill-down, because
+// the rows must be filled before filtering
+func (conf *Config) RowPatterns() bool {
+	return conf.ScopedPatterns() || conf.UseContext() || conf.InvertRows() || conf.FillDown != ""
+}
+
 // Parse config file.  Ignore if the file doesn't exist  but return an
 // error if it exists but fails to read or parse
 func (conf *Config) ParseConfigfile() error {
@@ -444,13 +1310,20 @@ func (conf *Config) ParseConfigfile() error {
 		return nil
 	}
 
-	configstring, err := os.ReadFile(path.Name())
+	configstring, err := os.ReadFile(conf.Configfile)
 	if err != nil {
 		return fmt.Errorf("failed to read config file %s: %w", path.Name(), err)
 	}
 
+	// the decoder determines the format by suffix, the default config
+	// file doesn't have one
+	filename := path.Name()
+	if !strings.HasSuffix(filename, ".json") {
+		filename = strings.TrimSuffix(filename, ".hcl") + ".hcl"
+	}
+
 	err = hclsimple.Decode(
-		path.Name(),
+		filename,
 		configstring,
 		nil,
 		&conf.Settings)
@@ -61,7 +61,8 @@ func TestPrepareSortFlags(t *testing.T) {
 		{Sortmode{Numeric: true}, "numeric"},
 		{Sortmode{Age: true}, "duration"},
 		{Sortmode{Time: true}, "time"},
-		{Sortmode{}, "string"},
+		{Sortmode{String: true}, "string"},
+		{Sortmode{}, "auto"},
 	}
 
 	for _, testdata := range tests {
@@ -136,3 +137,563 @@ func TestPreparePattern(t *testing.T) {
 		})
 	}
 }
+
+func TestPrepareTypes(t *testing.T) {
+	var tests = []struct {
+		name      string
+		types     []string
+		expect    map[string]int
+		wanterror bool
+	}{
+		{
+			name:   "single",
+			types:  []string{"age=duration"},
+			expect: map[string]int{"age": TypeDuration},
+		},
+		{
+			name:   "list",
+			types:  []string{"1=int,size=bytes", "when=timestamp"},
+			expect: map[string]int{"1": TypeInt, "size": TypeSize, "when": TypeTime},
+		},
+		{
+			name:      "unknown-type",
+			types:     []string{"age=weird"},
+			wanterror: true,
+		},
+		{
+			name:      "invalid-format",
+			types:     []string{"age"},
+			wanterror: true,
+		},
+	}
+
+	for _, testdata := range tests {
+		testname := fmt.Sprintf("PrepareTypes-%s", testdata.name)
+		t.Run(testname, func(t *testing.T) {
+			conf := Config{Rawtypes: testdata.types}
+
+			err := conf.PrepareTypes()
+
+			if testdata.wanterror {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.EqualValues(t, testdata.expect, conf.Types)
+			}
+		})
+	}
+}
+
+func TestPrepareOnError(t *testing.T) {
+	var tests = []struct {
+		policy    string
+		lenient   bool
+		expect    string
+		wanterror bool
+	}{
+		{"", false, "fail", false},
+		{"pad", false, "pad", false},
+		{"merge", false, "merge", false},
+		{"", true, "skip", false},
+		{"ignore", false, "", true},
+	}
+
+	for _, testdata := range tests {
+		testname := fmt.Sprintf("PrepareOnError-%s-lenient-%t", testdata.policy, testdata.lenient)
+		t.Run(testname, func(t *testing.T) {
+			conf := Config{OnError: testdata.policy, Lenient: testdata.lenient}
+
+			err := conf.PrepareOnError()
+
+			if testdata.wanterror {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.EqualValues(t, testdata.expect, conf.OnError)
+			}
+		})
+	}
+}
+
+func TestPrepareFilters(t *testing.T) {
+	var tests = []struct {
+		filter    string
+		expect    Filter
+		wanterror bool
+	}{
+		{"name=foo", Filter{Field: "name", Op: "=", Value: "foo"}, false},
+		{"Name!=foo", Filter{Field: "name", Op: "!=", Value: "foo", Negate: true}, false},
+		{"restarts>=10", Filter{Field: "restarts", Op: ">=", Value: "10"}, false},
+		{"cpu<0.5", Filter{Field: "cpu", Op: "<", Value: "0.5"}, false},
+		{"age>2d", Filter{Field: "age", Op: ">", Value: "2d"}, false},
+		{"size=1G..5G", Filter{Field: "size", Op: "=", Value: "1G..5G"}, false},
+		{"name=a=b", Filter{Field: "name", Op: "=", Value: "a=b"}, false},
+		{"name", Filter{}, true},
+		{"name=[a-", Filter{}, true},
+	}
+
+	for _, testdata := range tests {
+		testname := fmt.Sprintf("PrepareFilters-%s", testdata.filter)
+		t.Run(testname, func(t *testing.T) {
+			conf := Config{Rawfilters: []string{testdata.filter}}
+
+			err := conf.PrepareFilters()
+
+			if testdata.wanterror {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, conf.Filters, 1)
+
+				filter := conf.Filters[0][0]
+				filter.Regex = nil
+				assert.EqualValues(t, testdata.expect, filter)
+			}
+		})
+	}
+}
+
+func TestPrepareFilterGroups(t *testing.T) {
+	var tests = []struct {
+		filter string
+		expect []string // fields of the group members
+	}{
+		{"status=Error|restarts>5", []string{"status", "restarts"}},
+		{"status=Error|status=CrashLoop", []string{"status", "status"}},
+		{"name=foo|bar", []string{"name"}},
+		{"name=(foo|bar)", []string{"name"}},
+	}
+
+	for _, testdata := range tests {
+		testname := fmt.Sprintf("PrepareFilterGroups-%s", testdata.filter)
+		t.Run(testname, func(t *testing.T) {
+			conf := Config{Rawfilters: []string{testdata.filter}}
+
+			assert.NoError(t, conf.PrepareFilters())
+			assert.Len(t, conf.Filters, 1)
+
+			fields := []string{}
+			for _, filter := range conf.Filters[0] {
+				fields = append(fields, filter.Field)
+			}
+
+			assert.EqualValues(t, testdata.expect, fields)
+		})
+	}
+}
+
+func TestPrepareFilterSets(t *testing.T) {
+	conf := Config{
+		Rawfilters: []string{"name=api"},
+		UseFilters: []string{"broken"},
+		Settings: Settings{
+			FilterSets: []FilterSet{
+				{
+					Name:        "broken",
+					Patterns:    []string{"/prod/i"},
+					Filters:     []string{"status=Error|restarts>5"},
+					Expressions: []string{"age < 1h"},
+				},
+			},
+		},
+	}
+
+	assert.NoError(t, conf.PrepareFilters())
+	assert.Len(t, conf.Filters, 2)
+	assert.Len(t, conf.Filters[1], 2)
+	assert.EqualValues(t, []string{"age < 1h"}, conf.Expressions)
+	assert.EqualValues(t, []string{"/prod/i"}, conf.SetPatterns)
+
+	conf = Config{UseFilters: []string{"unknown"}}
+	assert.Error(t, conf.PrepareFilters())
+}
+
+func TestPreparePatternColumns(t *testing.T) {
+	var tests = []struct {
+		pattern        string
+		patterncolumns string
+		expectcolumns  string
+		expectpattern  string
+		expectscope    string // columns of the scoped variant, if any
+		expectscoped   string // pattern of the scoped variant
+	}{
+		{"name:/^api-/i", "", "", "name:/^api-/i", "name", "(?i)^api-"},
+		{"name,3:/^api-/", "", "", "name,3:/^api-/", "name,3", "^api-"},
+		{"/^api-/", "name", "name", "^api-", "", ""},
+		{"status:/Error/", "name", "name", "status:/Error/", "status", "Error"},
+		{"https://x.org/", "", "", "https://x.org/", "https", "/x.org"},
+		{"10:30", "", "", "10:30", "", ""},
+		{"api", "", "", "api", "", ""},
+	}
+
+	for _, testdata := range tests {
+		testname := fmt.Sprintf("PreparePatternColumns-%s-%s", testdata.pattern, testdata.patterncolumns)
+		t.Run(testname, func(t *testing.T) {
+			conf := Config{PatternColumns: testdata.patterncolumns}
+			patterns := []*Pattern{{Pattern: testdata.pattern}}
+
+			assert.NoError(t, conf.PreparePattern(patterns))
+			assert.EqualValues(t, testdata.expectcolumns, patterns[0].Columns)
+			assert.EqualValues(t, testdata.expectpattern, patterns[0].Pattern)
+			assert.EqualValues(t, testdata.expectcolumns != "" || testdata.expectscope != "",
+				conf.ScopedPatterns())
+
+			if testdata.expectscope == "" {
+				assert.Nil(t, patterns[0].Scope)
+			} else {
+				assert.EqualValues(t, testdata.expectscope, patterns[0].Scope.Columns)
+				assert.EqualValues(t, testdata.expectscoped, patterns[0].Scope.Pattern)
+			}
+		})
+	}
+}
+
+func TestPrepareKeyFilters(t *testing.T) {
+	var tests = []struct {
+		in        string
+		notin     string
+		expect    KeyFilter
+		wanterror bool
+	}{
+		{in: "node=nodes.txt", expect: KeyFilter{Column: "node", File: "nodes.txt"}},
+		{notin: "node=nodes.txt", expect: KeyFilter{Column: "node", File: "nodes.txt", Negate: true}},
+		{in: "node=nodes.csv:name", expect: KeyFilter{Column: "node", File: "nodes.csv", KeyColumn: "name"}},
+		{in: "node", wanterror: true},
+		{in: "=nodes.txt", wanterror: true},
+	}
+
+	for _, testdata := range tests {
+		testname := fmt.Sprintf("PrepareKeyFilters-in-%s-notin-%s", testdata.in, testdata.notin)
+		t.Run(testname, func(t *testing.T) {
+			conf := Config{}
+
+			if testdata.in != "" {
+				conf.RawIn = []string{testdata.in}
+			}
+
+			if testdata.notin != "" {
+				conf.RawNotIn = []string{testdata.notin}
+			}
+
+			err := conf.PrepareKeyFilters()
+
+			if testdata.wanterror {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.EqualValues(t, []KeyFilter{testdata.expect}, conf.KeyFilters)
+			}
+		})
+	}
+}
+
+func TestPrepareAggregations(t *testing.T) {
+	var tests = []struct {
+		groupby   string
+		agg       string
+		expect    []Aggregation
+		wanterror bool
+	}{
+		{"", "", []Aggregation{}, false},
+		{"status", "", []Aggregation{{Func: "count", Header: "count"}}, false},
+		{"status", "count, sum(restarts)", []Aggregation{
+			{Func: "count", Header: "count"},
+			{Func: "sum", Column: "restarts", Header: "sum_restarts"},
+		}, false},
+		{"status", "median(restarts)", nil, true},
+		{"status", "sum", nil, true},
+		{"status", "sum(", nil, true},
+	}
+
+	for _, testdata := range tests {
+		testname := fmt.Sprintf("PrepareAggregations-%s-%s", testdata.groupby, testdata.agg)
+		t.Run(testname, func(t *testing.T) {
+			conf := Config{GroupBy: testdata.groupby, RawAgg: testdata.agg}
+
+			err := conf.PrepareAggregations()
+
+			if testdata.wanterror {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.EqualValues(t, testdata.expect, conf.Aggregations)
+			}
+		})
+	}
+}
+
+func TestPrepareFooter(t *testing.T) {
+	var tests = []struct {
+		footer    string
+		expect    []Aggregation
+		wanterror bool
+	}{
+		{"", []Aggregation{}, false},
+		{"sum(restarts), count(name)", []Aggregation{
+			{Func: "sum", Column: "restarts", Header: "sum_restarts"},
+			{Func: "count", Column: "name", Header: "count_name"},
+		}, false},
+		{"count", []Aggregation{{Func: "count", Header: "count"}}, false},
+		{"median(restarts)", nil, true},
+		{"avg", nil, true},
+	}
+
+	for _, testdata := range tests {
+		testname := fmt.Sprintf("PrepareFooter-%s", testdata.footer)
+		t.Run(testname, func(t *testing.T) {
+			conf := Config{RawFooter: testdata.footer}
+
+			err := conf.PrepareFooter()
+
+			if testdata.wanterror {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.EqualValues(t, testdata.expect, conf.Footer)
+			}
+		})
+	}
+}
+
+func TestPreparePivot(t *testing.T) {
+	var tests = []struct {
+		pivot     string
+		agg       string
+		wanterror bool
+	}{
+		{"namespace:status", "", false},
+		{"namespace,node:status", "sum(restarts)", false},
+		{"namespace", "", true},
+		{"namespace:status", "count,sum(restarts)", true},
+		{"namespace:status", "median(x)", true},
+	}
+
+	for _, testdata := range tests {
+		testname := fmt.Sprintf("PreparePivot-%s-%s", testdata.pivot, testdata.agg)
+		t.Run(testname, func(t *testing.T) {
+			conf := Config{Pivot: testdata.pivot, RawPivotAgg: testdata.agg}
+
+			err := conf.PreparePivot()
+
+			if testdata.wanterror {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestPrepareComputed(t *testing.T) {
+	var tests = []struct {
+		raw       string
+		expect    Computed
+		wanterror bool
+	}{
+		{raw: "age_s = seconds(age)", expect: Computed{Name: "age_s", Expression: "seconds(age)"}},
+		{raw: "ok=restarts == 0", expect: Computed{Name: "ok", Expression: "restarts == 0"}},
+		{raw: "restarts == 0", wanterror: true},
+		{raw: "= 1", wanterror: true},
+		{raw: "name", wanterror: true},
+	}
+
+	for _, testdata := range tests {
+		testname := fmt.Sprintf("PrepareComputed-%s", testdata.raw)
+		t.Run(testname, func(t *testing.T) {
+			conf := Config{RawComputed: []string{testdata.raw}}
+
+			err := conf.PrepareComputed()
+
+			if testdata.wanterror {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.EqualValues(t, []Computed{testdata.expect}, conf.Computed)
+			}
+		})
+	}
+}
+
+func TestPrepareSplits(t *testing.T) {
+	var tests = []struct {
+		raw       string
+		column    string
+		search    string
+		names     []string
+		wanterror bool
+	}{
+		{raw: `restarts=/(\d+) \((.*) ago\)/count,last`, column: "restarts",
+			search: `(\d+) \((.*) ago\)`, names: []string{"count", "last"}},
+		{raw: `path=|(.*)/(.*)|`, column: "path", search: `(.*)/(.*)`},
+		{raw: `ready=/(\d+)/a,b`, wanterror: true},
+		{raw: `ready=/\d+/`, wanterror: true},
+		{raw: `ready=/(\d+/`, wanterror: true},
+		{raw: `ready=/(\d+)`, wanterror: true},
+		{raw: `=/(\d+)/`, wanterror: true},
+	}
+
+	for _, testdata := range tests {
+		testname := fmt.Sprintf("PrepareSplits-%s", testdata.raw)
+		t.Run(testname, func(t *testing.T) {
+			conf := Config{RawSplits: []string{testdata.raw}}
+
+			err := conf.PrepareSplits()
+
+			if testdata.wanterror {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.EqualValues(t, 1, len(conf.Splits))
+				assert.EqualValues(t, testdata.column, conf.Splits[0].Column)
+				assert.EqualValues(t, testdata.search, conf.Splits[0].Search.String())
+				assert.EqualValues(t, testdata.names, conf.Splits[0].Names)
+			}
+		})
+	}
+}
+
+func TestPrepareMerges(t *testing.T) {
+	var tests = []struct {
+		raw       string
+		expect    Merge
+		wanterror bool
+	}{
+		{raw: "ref={namespace}/{name}", expect: Merge{Name: "ref", Template: "{namespace}/{name}"}},
+		{raw: "ref = {1}={2}", expect: Merge{Name: "ref", Template: "{1}={2}"}},
+		{raw: "ref", wanterror: true},
+		{raw: "={1}", wanterror: true},
+		{raw: "ref=namespace/name", wanterror: true},
+	}
+
+	for _, testdata := range tests {
+		testname := fmt.Sprintf("PrepareMerges-%s", testdata.raw)
+		t.Run(testname, func(t *testing.T) {
+			conf := Config{RawMerges: []string{testdata.raw}}
+
+			err := conf.PrepareMerges()
+
+			if testdata.wanterror {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.EqualValues(t, []Merge{testdata.expect}, conf.Merges)
+			}
+		})
+	}
+}
+
+func TestPrepareRenames(t *testing.T) {
+	var tests = []struct {
+		rename    string
+		transform string
+		wanterror bool
+	}{
+		{rename: "cpu(cores)=cpu"},
+		{transform: "snake"},
+		{transform: "/[()]/_/"},
+		{transform: "|\\s+|_|"},
+		{rename: "cpu", wanterror: true},
+		{rename: "cpu=", wanterror: true},
+		{transform: "camel", wanterror: true},
+		{transform: "/[(/_/", wanterror: true},
+		{transform: "/a/b", wanterror: true},
+	}
+
+	for _, testdata := range tests {
+		testname := fmt.Sprintf("PrepareRenames-%s-%s", testdata.rename, testdata.transform)
+		t.Run(testname, func(t *testing.T) {
+			conf := Config{HeaderTransform: testdata.transform}
+
+			if testdata.rename != "" {
+				conf.RawRenames = []string{testdata.rename}
+			}
+
+			err := conf.PrepareRenames()
+
+			if testdata.wanterror {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestPrepareTransposers(t *testing.T) {
+	var tests = []struct {
+		raw       string
+		columns   string
+		function  string
+		replace   string
+		condition int // number of filters in the condition
+		wanterror bool
+	}{
+		{raw: `/a/b/`, replace: "b"},
+		{raw: `|a/|b|`},
+		{raw: `name:upper`, columns: "name", function: "upper"},
+		{raw: `^n.*:truncate(5)`, columns: "^n.*", function: "truncate"},
+		{raw: `age:/^.+$/stale/ if status=Completed`, columns: "age", condition: 1},
+		{raw: `age:lower if status=Completed|restarts>5`, columns: "age", function: "lower", condition: 2},
+		{raw: `/x/ok if y/`, replace: "ok if y"},
+		{raw: `/a if b/c/ if status=x`, replace: "c", condition: 1},
+		{raw: `age:/[a/b/`, wanterror: true},
+		{raw: `truncate`, wanterror: true},
+		{raw: `upper(5)`, wanterror: true},
+		{raw: `age:camel`, wanterror: true},
+		{raw: `age:upper if status`, wanterror: true},
+		{raw: `age:upper if status=[a`, wanterror: true},
+	}
+
+	for _, testdata := range tests {
+		testname := fmt.Sprintf("PrepareTransposers-%s", testdata.raw)
+		t.Run(testname, func(t *testing.T) {
+			conf := Config{Transposers: []string{testdata.raw}}
+
+			err := conf.PrepareTransposers()
+
+			if testdata.wanterror {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.EqualValues(t, testdata.columns, conf.UseTransposers[0].Columns)
+				assert.EqualValues(t, testdata.function, conf.UseTransposers[0].Function)
+				assert.EqualValues(t, testdata.condition, len(conf.UseTransposers[0].Condition))
+
+				if testdata.replace != "" {
+					assert.EqualValues(t, testdata.replace, conf.UseTransposers[0].Replace)
+				}
+			}
+		})
+	}
+}
+
+func TestPrepareMaps(t *testing.T) {
+	var tests = []struct {
+		raw       string
+		expect    ValueMap
+		wanterror bool
+	}{
+		{raw: "node=racks.yaml", expect: ValueMap{Column: "node", File: "racks.yaml"}},
+		{raw: "node=racks.csv:name:rack",
+			expect: ValueMap{Column: "node", File: "racks.csv", KeyColumn: "name", ValColumn: "rack"}},
+		{raw: "node=racks.csv:name:", wanterror: true},
+		{raw: "node", wanterror: true},
+		{raw: "=racks.csv", wanterror: true},
+	}
+
+	for _, testdata := range tests {
+		testname := fmt.Sprintf("PrepareMaps-%s", testdata.raw)
+		t.Run(testname, func(t *testing.T) {
+			conf := Config{RawMaps: []string{testdata.raw}}
+
+			err := conf.PrepareMaps()
+
+			if testdata.wanterror {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.EqualValues(t, []ValueMap{testdata.expect}, conf.Maps)
+			}
+		})
+	}
+}
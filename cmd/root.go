@@ -95,6 +95,18 @@ func Execute() {
 			conf.PrepareCustomHeaders(headers)
 
 			wrapE(conf.PrepareFilters())
+			wrapE(conf.PrepareKeyFilters())
+			wrapE(conf.PrepareMaps())
+			wrapE(conf.PrepareComputed())
+			wrapE(conf.PrepareRenames())
+			wrapE(conf.PrepareSplits())
+			wrapE(conf.PrepareMerges())
+			wrapE(conf.PrepareJoin())
+			wrapE(conf.PrepareAggregations())
+			wrapE(conf.PreparePivot())
+			wrapE(conf.PrepareFooter())
+			wrapE(conf.PrepareTypes())
+			wrapE(conf.PrepareOnError())
 
 			conf.DetermineColormode()
 			conf.ApplyDefaults()
@@ -121,6 +133,16 @@ func Execute() {
 		"Display manual page")
 	rootCmd.PersistentFlags().BoolVarP(&conf.UseFuzzySearch, "fuzzy", "z", false,
 		"Use fuzzy searching")
+	rootCmd.PersistentFlags().BoolVarP(&conf.FuzzyRank, "fuzzy-rank", "", false,
+		"Sort rows by fuzzy rank, best matches first (implies -z)")
+	rootCmd.PersistentFlags().StringVarP(&conf.PatternColumns, "pattern-columns", "", "",
+		"Match patterns only against the specified columns (separated by ,)")
+	rootCmd.PersistentFlags().IntVarP(&conf.BeforeContext, "before-context", "", 0,
+		"Show N rows before each pattern match")
+	rootCmd.PersistentFlags().IntVarP(&conf.AfterContext, "after-context", "", 0,
+		"Show N rows after each pattern match")
+	rootCmd.PersistentFlags().IntVarP(&conf.Context, "context", "", 0,
+		"Show N rows before and after each pattern match")
 	rootCmd.PersistentFlags().BoolVarP(&conf.UseHighlight, "highlight-lines", "L", false,
 		"Use alternating background colors")
 	rootCmd.PersistentFlags().StringVarP(&ShowCompletion, "completion", "", "",
@@ -144,10 +166,69 @@ func Execute() {
 	rootCmd.PersistentFlags().StringVarP(&headers, "custom-headers", "x", "",
 		"Custom headers")
 
+	// join options
+	rootCmd.PersistentFlags().StringVarP(&conf.Join, "join", "", "",
+		"Join input with the table in the given file")
+	rootCmd.PersistentFlags().StringVarP(&conf.JoinOn, "on", "", "",
+		"Key columns to join on (column or leftcolumn=rightcolumn)")
+	rootCmd.PersistentFlags().StringVarP(&conf.JoinType, "join-type", "", "inner",
+		"Join type: inner|left|full")
+	rootCmd.PersistentFlags().StringVarP(&conf.JoinPrefix, "join-prefix", "", "right_",
+		"Prefix for headers of the joined table which exist in the input")
+
+	// group options
+	rootCmd.PersistentFlags().StringVarP(&conf.GroupBy, "group-by", "", "",
+		"Group rows by the specified columns (separated by ,)")
+	rootCmd.PersistentFlags().StringVarP(&conf.RawAgg, "agg", "", "",
+		"Aggregations per group, e.g. 'count,sum(restarts),avg(cpu),max(age)'")
+
+	// pivot options
+	rootCmd.PersistentFlags().StringVarP(&conf.Pivot, "pivot", "", "",
+		"Pivot table, rows by the first, columns by the second column (rowcolumns:column)")
+	rootCmd.PersistentFlags().StringVarP(&conf.RawPivotAgg, "pivot-agg", "", "count",
+		"Aggregation used for pivot cells, e.g. 'sum(restarts)'")
+	rootCmd.PersistentFlags().StringVarP(&conf.PivotFill, "pivot-fill", "", "",
+		"Value used for empty pivot cells")
+	rootCmd.PersistentFlags().StringVarP(&conf.Unpivot, "unpivot", "", "",
+		"Turn all columns except the specified ones into key/value rows")
+
+	// footer options
+	rootCmd.PersistentFlags().StringVarP(&conf.RawFooter, "footer", "", "",
+		"Add a footer row with aggregations, e.g. 'sum(restarts),count(name)'")
+
+	// statistics options
+	rootCmd.PersistentFlags().BoolVarP(&conf.Stats, "stats", "", false,
+		"Print statistics per column instead of the table")
+	rootCmd.PersistentFlags().IntVarP(&conf.StatsTop, "stats-top", "", 3,
+		"Number of most frequent values shown by --stats")
+
 	// sort options
 	rootCmd.PersistentFlags().StringVarP(&conf.SortByColumn, "sort-by", "k", "",
 		"Sort by column (default: 1)")
 
+	// deduplicate rows
+	rootCmd.PersistentFlags().StringVarP(&conf.Unique, "unique", "", "",
+		"Only show one row per unique value of the specified columns (separated by ,)")
+	rootCmd.PersistentFlags().BoolVarP(&conf.UniqueLast, "unique-last", "", false,
+		"Keep the last row per unique value instead of the first one")
+	rootCmd.PersistentFlags().BoolVarP(&conf.UniqueCount, "unique-count", "", false,
+		"Add a COUNT column containing the number of rows per unique value")
+
+	rootCmd.PersistentFlags().BoolVarP(&conf.Rotate, "rotate", "", false,
+		"Rotate the table: headers become the first column, rows become columns")
+
+	// limit rows after sorting
+	rootCmd.PersistentFlags().IntVarP(&conf.Offset, "offset", "", 0,
+		"Skip the first N rows")
+	rootCmd.PersistentFlags().IntVarP(&conf.Head, "head", "", 0,
+		"Only show the first N rows")
+	rootCmd.PersistentFlags().IntVarP(&conf.Tail, "tail", "", 0,
+		"Only show the last N rows")
+	rootCmd.PersistentFlags().IntVarP(&conf.Sample, "sample", "", 0,
+		"Only show N randomly selected rows")
+	rootCmd.PersistentFlags().Uint64VarP(&conf.Seed, "seed", "", 0,
+		"Random seed used by --sample (default: random)")
+
 	// sort mode, only 1 allowed
 	rootCmd.PersistentFlags().BoolVarP(&conf.SortDescending, "sort-desc", "D", false,
 		"Sort in descending order (default: ascending)")
@@ -157,8 +238,10 @@ func Execute() {
 		"sort according to time string")
 	rootCmd.PersistentFlags().BoolVarP(&sortmode.Age, "sort-age", "a", false,
 		"sort according to age (duration) string")
+	rootCmd.PersistentFlags().BoolVarP(&sortmode.String, "sort-string", "", false,
+		"sort alphanumerically instead of according to the column type")
 	rootCmd.MarkFlagsMutuallyExclusive("sort-numeric", "sort-time",
-		"sort-age")
+		"sort-age", "sort-string")
 
 	// output flags, only 1 allowed
 	rootCmd.PersistentFlags().BoolVarP(&modeflag.X, "extended", "X", false,
@@ -186,13 +269,52 @@ func Execute() {
 
 	// filters
 	rootCmd.PersistentFlags().StringArrayVarP(&conf.Rawfilters,
-		"filter", "F", nil, "Filter by field (field=regexp || field!=regexp)")
+		"filter", "F", nil, "Filter by field (field=regexp || field!=regexp || field<=value, also <,>,>=, field=low..high)")
+	rootCmd.PersistentFlags().StringArrayVarP(&conf.UseFilters,
+		"use-filter", "", nil, "Use filter set defined in config file")
+	rootCmd.PersistentFlags().BoolVarP(&conf.AnyPattern, "any", "", false,
+		"Show rows matching any of the positional patterns instead of all of them")
+	rootCmd.PersistentFlags().StringArrayVarP(&conf.Expressions,
+		"expr", "E", nil, "Filter rows by expression (e.g. 'restarts > 5 && age < 1h')")
+	rootCmd.PersistentFlags().StringArrayVarP(&conf.RawComputed,
+		"add", "", nil, "Add a computed column (name=expression, e.g. 'age_s = seconds(age)')")
+	rootCmd.PersistentFlags().StringVarP(&conf.FillDown, "fill-down", "", "",
+		"Fill empty cells of the specified columns (separated by ,) with the last non-empty value above")
+	rootCmd.PersistentFlags().StringArrayVarP(&conf.RawRenames,
+		"rename", "", nil, "Rename a column (column=name)")
+	rootCmd.PersistentFlags().StringVarP(&conf.HeaderTransform, "header-transform", "", "",
+		"Transform all headers: lower|upper|snake or /regexp/replace-string/")
+	rootCmd.PersistentFlags().StringArrayVarP(&conf.RawSplits,
+		"split", "", nil, "Split a column into new ones using regexp capture groups (column=/regexp/name,...)")
+	rootCmd.PersistentFlags().BoolVarP(&conf.SplitKeep, "split-keep", "", false,
+		"Keep the source column of --split")
+	rootCmd.PersistentFlags().StringArrayVarP(&conf.RawMerges,
+		"merge", "", nil, "Merge columns into a new one using a template (name=template, e.g. 'ref={namespace}/{name}')")
+	rootCmd.PersistentFlags().BoolVarP(&conf.MergeRemove, "merge-remove", "", false,
+		"Remove the source columns of --merge")
+	rootCmd.PersistentFlags().StringArrayVarP(&conf.RawIn,
+		"in", "", nil, "Only show rows whose column value appears in file (column=file[:keycolumn])")
+	rootCmd.PersistentFlags().StringArrayVarP(&conf.RawNotIn,
+		"not-in", "", nil, "Only show rows whose column value doesn't appear in file (column=file[:keycolumn])")
+	rootCmd.PersistentFlags().StringArrayVarP(&conf.RawMaps,
+		"map", "", nil, "Replace values by the ones looked up in a dictionary file (column=file[:keycolumn:valuecolumn])")
+	rootCmd.PersistentFlags().BoolVarP(&conf.MapAdd, "map-add", "", false,
+		"Add the looked up values of --map as new column instead of replacing them")
+	rootCmd.PersistentFlags().StringVarP(&conf.MapDefault, "map-default", "", "",
+		"Value used by --map for values not found in the dictionary (default: keep value)")
+	rootCmd.PersistentFlags().StringArrayVarP(&conf.Rawtypes,
+		"types", "", nil, "Override inferred column types (column=type)")
 	rootCmd.PersistentFlags().StringArrayVarP(&conf.Transposers,
-		"regex-transposer", "R", nil, "apply /search/replace/ regexp to fields given in -T")
+		"regex-transposer", "R", nil, "apply /search/replace/ regexp or upper|lower|trim|truncate(n) to fields given in -T or as prefix (col:rule)")
 
 	// input
 	rootCmd.PersistentFlags().StringVarP(&conf.InputFile, "read-file", "r", "",
 		"Read input data from file")
+	rootCmd.PersistentFlags().StringVarP(&conf.OnError, "on-error", "", "fail",
+		"What to do with malformed rows: fail|skip|pad|merge")
+	rootCmd.PersistentFlags().BoolVarP(&conf.Lenient, "lenient", "", false,
+		"Report and skip malformed rows, same as --on-error=skip")
+	rootCmd.MarkFlagsMutuallyExclusive("on-error", "lenient")
 
 	rootCmd.SetUsageTemplate(strings.TrimSpace(usage) + "\n")
 
@@ -13,6 +13,23 @@ const shortusage = `tablizer [regex,...] [-r file] [flags]
 -x col,...   use custom headers                      -d  debug
 -o char      use char as output separator            -g  auto generate headers
 
+-E expr           filter rows by expression          --any        match any
+--in col=file     only rows whose col is in file     --not-in     negated --in
+--use-filter name use filter set from config         --context n  around match
+--on-error how    handle malformed rows              --fuzzy-rank rank matches
+
+--add name=expr   add computed column                --rename     rename column
+--map col=file    replace values from dictionary     --split      split column
+--merge name=tpl  merge columns into a new one       --fill-down  fill empty
+--types col=type  override column type               --header-transform
+
+--join file       join with table, see --on          --group-by   see --agg
+--pivot rows:col  pivot table, see --pivot-agg       --unpivot    to key/value
+--footer aggs     add footer row, eg 'sum(col)'      --stats      statistics
+--head n          show first n rows, also --tail     --unique     unique rows
+--sample n        show n random rows                 --rotate     rotate table
+
 -O org -C CSV -M md -X ext -S shell -Y yaml -J json  -D  sort descending order
 -m  show manual       --help  show detailed help     -v  show version
--a  sort by age       -i      sort numerically       -t  sort by time`
+-a  sort by age       -i      sort numerically       -t  sort by time
+--sort-string         sort alphanumerically instead of by column type`
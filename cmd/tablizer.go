@@ -17,13 +17,51 @@ SYNOPSIS
           -s, --separator <string>           Custom field separator (maybe char, string or :class:)
           -k, --sort-by <int|name>           Sort by column (default: 1)
           -z, --fuzzy                        Use fuzzy search [experimental]
-          -F, --filter <field[!]=reg>        Filter given field with regex, can be used multiple times
+              --fuzzy-rank                   Sort rows by fuzzy rank, implies -z
+          -F, --filter <field[!]=reg>        Filter given field with regex or <,>,<=,>=, can be used multiple times
+          -E, --expr <expression>            Filter rows by expression, can be used multiple times
+              --add <name=expression>        Add a computed column, can be used multiple times
+              --fill-down <cols>             Fill empty cells with the last non-empty value above
+              --rename <col=name>            Rename a column, can be used multiple times
+              --header-transform <how>       Transform headers: lower|upper|snake|/regex/replace/
+              --map <col=file[:key:val]>     Replace values by the ones looked up in a dictionary
+              --map-add                      Add looked up values as new column instead
+              --map-default <value>          Value used for values not found in the dictionary
+              --split <col=/regex/names>     Split a column into new ones using capture groups
+              --split-keep                   Keep the source column of --split
+              --merge <name=template>        Merge columns into a new one, eg 'ref={namespace}/{name}'
+              --merge-remove                 Remove the source columns of --merge
+              --use-filter <name>            Use a filter set defined in the config file
+              --in <col=file[:keycol]>       Only show rows whose col value appears in file
+              --not-in <col=file[:keycol]>   Only show rows whose col value doesn't appear in file
+              --any                          Show rows matching any positional pattern instead of all
+              --pattern-columns <cols>       Match patterns only against the given columns
+              --before-context <n>           Show n rows before each pattern match
+              --after-context <n>            Show n rows after each pattern match
+              --context <n>                  Show n rows before and after each pattern match
           -T, --transpose-columns string     Transpose the speficied columns (separated by ,)
-          -R, --regex-transposer </from/to/> Apply /search/replace/ regexp to fields given in -T
+          -R, --regex-transposer </from/to/> Apply /search/replace/ regexp or function to fields given in -T
+                                             or to columns given as prefix (col:/from/to/)
           -j, --json                         Read JSON input (must be array of hashes)
           -I, --interactive                  Interactively filter and select rows
           -g, --auto-headers                 Generate headers if there are none present in input
           -x, --custom-headers a,b,...       Use custom headers, separated by comma
+              --join <file>                  Join input with the table in file
+              --on <col[=col]>               Key columns to join on
+              --join-type <type>             Join type: inner|left|full (default: inner)
+              --join-prefix <prefix>         Prefix for colliding headers (default: right_)
+              --group-by <cols>              Group rows by the given columns
+              --agg <aggregations>           Aggregations per group, eg 'count,sum(restarts)'
+              --footer <aggregations>        Add a footer row, eg 'sum(restarts),count(name)'
+              --stats                        Print statistics per column instead of the table
+              --stats-top <n>                Number of most frequent values (default: 3)
+              --pivot <rowcols:col>          Pivot table, rows by rowcols, columns by col
+              --pivot-agg <aggregation>      Aggregation used for pivot cells (default: count)
+              --pivot-fill <value>           Value used for empty pivot cells
+              --unpivot <cols>               Turn all other columns into key/value rows
+              --types <col=type>             Override the inferred type of a column
+              --on-error <policy>            What to do with malformed rows: fail|skip|pad|merge
+              --lenient                      Report and skip malformed rows (--on-error=skip)
 
         Output Flags (mutually exclusive):
           -X, --extended                     Enable extended output
@@ -38,12 +76,24 @@ SYNOPSIS
           -o, --ofs <char>                   Output field separator, used by -A and -C. 
           -y, --yank-columns                 Yank specified columns (separated by ,) to clipboard,
                                              space separated
+              --rotate                       Rotate table, rows become columns
 
         Sort Mode Flags (mutually exclusive):
           -a, --sort-age                     sort according to age (duration) string
           -D, --sort-desc                    Sort in descending order (default: ascending)
           -i, --sort-numeric                 sort according to string numerical value
           -t, --sort-time                    sort according to time string
+              --sort-string                  sort alphanumerically instead of by column type
+
+        Row Limit Flags:
+              --head <n>                     Only show the first n rows
+              --tail <n>                     Only show the last n rows
+              --offset <n>                   Skip the first n rows
+              --sample <n>                   Only show n randomly selected rows
+              --seed <n>                     Random seed used by --sample
+              --unique <cols>                Only show one row per unique value of cols
+              --unique-last                  Keep the last row per value instead of the first
+              --unique-count                 Add a COUNT column with the number of rows per value
 
         Other Flags:
           -r  --read-file <file>             Use <file> as input instead of STDIN
@@ -129,8 +179,12 @@ DESCRIPTION
     this column, you'll have to specify "-k4".
 
     The default sort order is ascending. You can change this to descending
-    order using the option -D. The default sort order is by alphanumeric
-    string, but there are other sort modes:
+    order using the option -D. By default every column is sorted according
+    to its type (see "COLUMN TYPES"), but you can force a sort mode for all
+    sort columns:
+
+    --sort-string
+        Sorts alphanumerically, eg 100 before 9.
 
     -a --sort-age
         Sorts duration strings like "1d4h32m51s".
@@ -141,9 +195,59 @@ DESCRIPTION
     -t --sort-time
         Sorts timestamps.
 
+    After sorting, the number of rows can be limited using --offset n (skip
+    the first n rows), --head n (show the first n rows), --tail n (show the
+    last n rows) and --sample n (show n randomly selected rows, retaining
+    their order), applied in this order. Use --seed to get reproducible
+    samples. Since this is done by tablizer itself, the output remains a
+    proper table in every output mode, eg the 10 pods with the most
+    restarts:
+
+        kubectl get pods -A | tablizer -k restarts -D --head 10 -M
+
+    Like sort -u you can remove duplicate rows using --unique, which takes a
+    list of columns in the same format as -c. Only the first row for every
+    unique combination of values of these columns is shown, or the last one
+    with --unique-last, the kept row stays at its position, so the order of
+    the rows is retained. This is done after sorting and before limiting the
+    rows. With --unique-count a column COUNT is added containing the number
+    of rows per combination, eg to list the images used in a cluster:
+
+        kubectl get pods -A -o wide | tablizer --unique image --unique-count -c image
+
     Finally the -d option enables debugging output which is mostly useful
     for the developer.
 
+  COLUMN TYPES
+    After parsing the input, tablizer determines the type of every column by
+    looking at a sample of its values. Empty values and placeholders like
+    "-" or "<none>" are ignored. A column gets a type if all sampled values
+    are of that type, otherwise it is considered to be a string column. The
+    following types are supported:
+
+        int       integer numbers like 42
+        float     floating point numbers like 0.5
+        bool      true, false, yes or no
+        duration  durations like 1d4h32m51s, 1.5h or 500ms
+        size      byte sizes like 512Mi, 1.5G or 100KB
+        ip        IPv4 or IPv6 addresses
+        time      timestamps like 2024-11-18T12:00:00+01:00
+        string    anything else
+
+    The column type is used when sorting, by the JSON and YAML output modes
+    (numbers and booleans are not being quoted) and by the interactive mode.
+
+    Sizes using units with an "i" (like "Gi") or without "B" (like "G") are
+    considered to be binary units (1024 based), sizes with "B" (like "GB")
+    decimal units (1000 based).
+
+    If the type inference guesses wrong, you can override the type of a
+    column using the option --types. It may be specified multiple times or
+    contain a comma separated list of column=type pairs. The column can be
+    specified by name, number or regex, just like with -c:
+
+        kubectl get pods | tablizer --types restarts=string,age=duration -k restarts
+
   SEPARATOR
     The option -s can be a single character, in which case the CSV parser
     will be invoked. You can also specify a string as separator. The string
@@ -195,6 +299,138 @@ DESCRIPTION
 
         Matches one or more non-printable characters.
 
+  JOINING TABLES
+    You can join the input with another table using --join file. The key
+    columns are given with --on column if both tables use the same header or
+    --on leftcolumn=rightcolumn otherwise, eg:
+
+        kubectl top pods > top.txt
+        kubectl get pods | tablizer --join top.txt --on name
+
+    The other table is parsed using the current separator, files ending in
+    ".csv" are parsed as CSV and files ending in ".json" as JSON. Its
+    columns are appended to the input columns, except the key column.
+    Headers which already exist in the input get the prefix given with
+    --join-prefix, which is "right_" by default. If the prefixed header
+    exists as well, tablizer aborts with an error, use another prefix in
+    this case.
+
+    By default an inner join is done, that is, only rows with a key found in
+    both tables are shown. Use --join-type left to keep input rows without a
+    partner and --join-type full to keep rows of both tables without a
+    partner. Missing values are left empty. If a key appears multiple times,
+    a row for every combination is shown.
+
+    The joined table can be filtered, sorted and printed like any other
+    input.
+
+  GROUPING AND AGGREGATION
+    Rows can be grouped by one or more columns using --group-by, which takes
+    a list of columns in the same format as -c. The output then contains one
+    row per group with the group columns and the aggregations given with
+    --agg, eg:
+
+        kubectl get pods | tablizer --group-by status --agg 'count,sum(restarts),avg(cpu),max(age)'
+
+    The following aggregate functions are supported:
+
+        count            number of rows in the group
+        count(column)    number of non-empty values
+        sum(column)      sum of the values
+        avg(column)      average of the values
+        min(column)      smallest value
+        max(column)      largest value
+
+    The aggregations respect the type of the column (see COLUMN TYPES), so
+    durations and sizes are summed up correctly and printed in the same
+    format, eg "1d2h". Floating point numbers are printed with full
+    precision and timestamps in UTC. sum and avg are only possible on
+    numeric, duration and size columns, min and max work with any type.
+    Empty values are ignored. The new columns are named after the
+    aggregation, eg "count" or "sum_restarts". If --agg is omitted, the rows
+    are counted. Without --group-by all rows are aggregated into one.
+
+    Grouping is done after filtering, the result can be sorted, reduced
+    using -c and printed in every output mode.
+
+  FOOTER
+    Reports often need totals below the table. Use --footer with the same
+    aggregations as --agg to add a footer row, eg:
+
+        kubectl get pods | tablizer --footer 'sum(restarts),count(name)' -O
+
+    The footer is calculated after filtering and --unique, but before the
+    rows are limited using --head, --tail, --offset or --sample, so it
+    always covers all matching rows. Every value is placed below its column
+    and prefixed with the function, eg "sum: 106", a count without column is
+    placed below the first column. The table modes print it as a footer row,
+    CSV as the last row and markdown as the last row in bold. Extended and
+    shell mode print the values named like the aggregations, eg
+    "sum_restarts". YAML output contains an additional "footer" map. JSON
+    output becomes an object containing the list of rows as "entries" and
+    the "footer" object, without --footer it stays a plain list of rows.
+
+  STATISTICS
+    Use --stats to get an overview of the input: instead of the table one
+    row per column is printed, containing the inferred type (see COLUMN
+    TYPES), the number of values, of distinct and of empty values and the
+    smallest and largest value. Numeric, duration and size columns also
+    contain the mean, the median and the percentiles "p90" and "p99". String
+    columns contain the most frequent values with their count, --stats-top
+    determines how many (default: 3), eg:
+
+        kubectl get pods | tablizer --stats -c column,type,min,max,top
+
+    The statistics are calculated after filtering, grouping and the like.
+    The result is an ordinary table, so it can be reduced with -c, sorted
+    and printed in every output mode, eg as JSON.
+
+  PIVOT TABLES
+    Use --pivot rowcolumns:column to turn long data into a matrix (also
+    known as crosstab). The result contains one row per unique value of the
+    row columns and one column per unique value of the pivot column. The
+    cells contain the aggregation of all matching rows given with
+    --pivot-agg, which accepts the same functions as --agg and defaults to
+    "count". Cells without matching rows are empty or contain the value
+    given with --pivot-fill, eg:
+
+        kubectl get pods -A | tablizer --pivot namespace:status --pivot-fill 0
+
+        NAMESPACE     Running   Error
+        kube-system   12        0
+        monitoring    7         1
+
+    Empty values of the pivot column get the header "EMPTY". If a header
+    already exists, eg because a value equals the name of a row column, a
+    number is appended to make it unique, eg "namespace_2".
+
+    The opposite is done using --unpivot columns: all columns except the
+    given ones are turned into rows containing these columns, a column "key"
+    with the original header and a column "value" with the original value.
+
+  MALFORMED INPUT
+    By default tablizer aborts if a row contains more fields than there are
+    headers. Rows in tabular input with less fields are always filled up
+    with empty fields, regardless of the policy, while short rows in CSV
+    input are considered to be malformed as well.
+
+    You can change this behavior using the option --on-error, which accepts
+    one of these policies:
+
+        fail   abort with an error, this is the default
+        skip   ignore malformed rows
+        pad    fill up short rows, drop excess fields from long rows
+        merge  fill up short rows, merge excess fields into the last field
+
+    The option --lenient is a shortcut for --on-error=skip.
+
+    Unless the policy is fail, every malformed row is reported on STDERR
+    along with its line number in the input and a summary count is printed
+    at the end, e.g.:
+
+        line 5031: expected 6 fields but got 7, skipped
+        1 malformed input rows (--on-error=skip)
+
   PATTERNS AND FILTERING
     You can reduce the rows being displayed by using one or more regular
     expression patterns. The regexp language being used is the one of
@@ -232,9 +468,44 @@ DESCRIPTION
 
     The flags can also be combined.
 
+    Patterns are matched against the whole input line, so searching for 1
+    matches every row containing a 1 somewhere. You can restrict a pattern
+    to one or more columns by prefixing the slash enclosed regexp with a
+    column list, separated by colon, eg:
+
+        kubectl get pods | tablizer 'name:/^api-/i' 'restarts,age:/^1/'
+
+    The column list uses the same format as -c. Such a pattern matches if it
+    matches any of the given columns. If the column list doesn't match any
+    column, the whole string is used as regexp, so that eg "https://x.org/"
+    still works as before. You can also restrict all patterns without a
+    column list using --pattern-columns, eg:
+
+        kubectl get pods | tablizer --pattern-columns name '/^api-/'
+
+    Matches of restricted patterns are highlighted only inside their
+    columns.
+
+    Like grep -B, -A and -C you can show the rows around each pattern match
+    using --before-context n, --after-context n or --context n, which sets
+    both. Overlapping context rows are only shown once. In colored output
+    the context rows are printed faint, so that they can be distinguished
+    from the actual matches.
+
     You can also use the experimental fuzzy search feature by providing the
     option -z, in which case the pattern is regarded as a fuzzy search term,
-    not a regexp.
+    not a regexp. A fuzzy term matches if all of its characters appear in
+    the same order, eg "kst" matches "kube-state". If multiple terms are
+    given, all of them have to match (or any of them with --any), negation
+    using "/term/!" and restricting terms to columns using "name:/term/"
+    work as with regexps. The matched characters are highlighted in the
+    output.
+
+    With --fuzzy-rank the rows are sorted by fuzzy rank, best matches first.
+    The rank is the number of characters skipped by the tightest match of
+    the terms in the row, or in the best matching column for restricted
+    terms, so "kst" ranks "kube-state-metrics" above "kaaasaaat". An
+    explicit sort column given with -k takes precedence.
 
     Sometimes you want to filter by one or more columns. You can do that
     using the -F option. The option can be specified multiple times and has
@@ -251,7 +522,212 @@ DESCRIPTION
 
         fieldname!=regexp
 
-    If the option -v is specified, the filtering is inverted.
+    Instead of a regexp you can also compare the field value using one of
+    the operators "<", "<=", ">" or ">=". The comparison is done according
+    to the type of the column (see COLUMN TYPES), eg:
+
+        -F 'restarts>=10' -F 'cpu<0.5' -F 'age>2d'
+
+    If the column is a string column, the type of the filter value is used
+    instead, so "-F 'restarts>=10'" compares numerically even if some values
+    are not numbers. Values which cannot be converted to that type never
+    match. Only if the filter value is a string as well, the values are
+    compared alphanumerically.
+
+    On typed columns a filter value of the form "low..high" selects an
+    inclusive range, eg "-F size=1G..5G". On string columns such a value is
+    treated as a regexp as usual. Multiple filters on the same field can be
+    used to specify a range as well: "-F 'age"1h' -F 'age<2d'>.
+
+    Multiple filters separated by "|" form an OR-group, which matches if any
+    of its filters matches, eg:
+
+        -F 'status=Error|status=CrashLoop' -F 'restarts>5|age<1h'
+
+    This shows rows with an error status, which either restarted more than 5
+    times or are younger than one hour. The value is only split into a group
+    if every part looks like a filter, so a regexp like "-F name=foo|bar"
+    keeps working.
+
+    Positional patterns are combined using AND as well. Use the option --any
+    to show rows matching any of the patterns instead. --any only applies to
+    positional patterns, field filters given with -F and expressions given
+    with -E are still combined using AND, use an OR-group or "||" inside an
+    expression to combine them with OR.
+
+    If the option -v is specified, the filtering is inverted. If patterns,
+    field filters and expressions are used together, -v is applied once to
+    their combination, that is all rows are shown, which don't match all of
+    them.
+
+  KEY FILTERS
+    Sometimes you want to keep only rows whose value in some column appears
+    in another file, eg pods running on nodes which are about to be drained.
+    Use --in column=file to do this, or --not-in column=file to drop those
+    rows instead:
+
+        kubectl get pods -o wide | tablizer --in node=nodes-to-drain.txt
+
+    The file contains one value per line, empty lines and lines starting
+    with "#" are ignored. You can also use another table as key set by
+    appending the key column to the filename, eg --in node=nodes.txt:name.
+    The table is parsed using the default separator (2 or more spaces or a
+    tab) regardless of -s, files ending in ".csv" are parsed as CSV and
+    files ending in ".json" as JSON. Both options can be used multiple
+    times, all of them have to match.
+
+  EXPRESSION FILTERS
+    More complex conditions can be expressed using the option -E, which
+    takes an expression and only shows the rows for which it is true, eg:
+
+        kubectl get pods | tablizer -E 'restarts > 5 && status != "Running" || age < 1h'
+
+    Columns are referenced by their (case insensitive) header name, by
+    number as $3 or as "${NAME}" if the header contains special characters.
+    Values are compared according to the type of the column (see COLUMN
+    TYPES), so "restarts > 5" is a numeric comparison and "age < 1h"
+    compares durations. Literals can be numbers, durations ("1h", "2d4h"),
+    sizes ("5G", "512Mi"), quoted strings or "true" and "false". Timestamps
+    must be quoted, eg "created > "2024-01-01"".
+
+    The following operators are supported:
+
+        +, -, *, /, %              arithmetic
+        ==, =, !=, <, <=, >, >=    comparison
+        contains                   substring match
+        =~, matches, !~            regexp match against a quoted regexp
+        in ("a", "b")              value is one of the listed ones
+        &&, and, ||, or, !, not    boolean logic
+        ( )                        grouping
+
+    If -E is specified multiple times, all expressions have to match. The
+    option -v inverts the result, just like with -F.
+
+  COMPUTED COLUMNS
+    New columns can be derived from existing ones using the option --add,
+    which takes a column name and an expression (see EXPRESSION FILTERS)
+    evaluated for every row, eg:
+
+        kubectl get pods | tablizer --add 'age_s = seconds(age)' -k age_s -c name,age_s
+
+    Computed columns are added before filtering, so they can be used with
+    -F, -E, -c, -k and in the interactive mode like any other column. If
+    --add is used multiple times, the expressions may refer to columns
+    computed before. If a column with the given name already exists, its
+    values are replaced.
+
+    Arithmetic honours the column types: "age + 30m" is a duration, "size *
+    2" a size and the difference of two timestamps a duration. The "+"
+    operator concatenates strings. Values which cannot be calculated, eg a
+    division by zero, result in an empty cell.
+
+    The following functions are available:
+
+        seconds(x)              duration or timestamp in seconds
+        duration(x)             seconds as duration, eg 1h30m
+        bytes(x)                size in bytes
+        size(x)                 bytes as size, eg 1.5Gi
+        now()                   the current time
+        since(x)                duration since timestamp x
+        round(x[, places])      round a number
+        abs(x)                  absolute value
+        len(x)                  length of a string
+        upper(x), lower(x)      change case
+        trim(x)                 remove leading and trailing whitespace
+        substr(x, start[, n])   n characters starting at start (0-based)
+        replace(x, old, new)    replace all occurrences of old
+        concat(x, ...)          concatenate all arguments
+
+  FILLING EMPTY CELLS
+    Hierarchical output like grouped reports or CSV exports of merged cells
+    only contain a value in the first row of a group and leave the cells
+    below empty. Use --fill-down with a comma separated list of columns to
+    copy the last non-empty value downwards:
+
+        tablizer -s, -r disks.csv --fill-down disk -F disk=sdb
+
+    This happens before filtering and sorting, so patterns and filters by
+    the parent value work on every row.
+
+  RENAMING HEADERS
+    Headers like "CPU(cores)" or "NOMINATED NODE" are awkward to use with
+    other options or as keys in YAML, JSON or shell output. Use --rename to
+    give a column, specified by header name or number, a new name:
+
+        kubectl top pods | tablizer --rename 'cpu(cores)=cpu' -F 'cpu>100'
+
+    To change all headers at once use --header-transform, which accepts
+    "lower", "upper", "snake" (eg "NOMINATED NODE" becomes "nominated_node")
+    or a "/search/replace/" regexp like with -R. Renamed columns are not
+    transformed.
+
+    Headers are renamed right after parsing, so all other options like -F,
+    -c, -k or --types have to use the new names. The only exception is --on,
+    which refers to the original headers.
+
+  MAPPING VALUES
+    Codes like node names, user ids or status codes can be mapped to human
+    readable names using a dictionary file with the option --map:
+
+        kubectl get pods -o wide | tablizer --map node=racks.csv
+
+    Dictionaries ending in ".yaml", ".yml" or ".hcl" must contain plain key
+    value pairs like "node1: rack-a" or "node1 = "rack-a"". Other files are
+    read as table like with --in, using the first column as key and the
+    second one as value. Other columns can be specified with "--map
+    node=nodes.csv:name:rack". The first line of such a table is always used
+    as header line, so a dictionary without headers has to get one added,
+    otherwise its first entry is lost.
+
+    By default the values are replaced, values not found in the dictionary
+    are kept. With --map-add the looked up values are added as a new column
+    named after the value column of the dictionary or "column_mapped" if the
+    dictionary has no headers. Use --map-default to set the value used for
+    values not found in the dictionary.
+
+    Mapping happens before computed columns and filters, so -F, -E and --add
+    can use the mapped values.
+
+  SPLITTING COLUMNS
+    Some programs pack multiple values into one cell, like the "RESTARTS"
+    column of "kubectl get pods", which contains values like "35 (45m ago)".
+    Such columns can be split into new columns using the option --split,
+    which takes a column, a regexp and a comma separated list of names for
+    the new columns, one per capture group:
+
+        kubectl get pods | tablizer --split 'restarts=/(\d+) \((.*) ago\)/count,last'
+        NAME                                          READY   STATUS    count  last  AGE
+        alertmanager-kube-prometheus-alertmanager-0   2/2     Running   35     45m   11d
+
+    Like with -R the first character after the "=" is used as delimiter, so
+    you can use eg "--split 'path=|(.*)/(.*)|dir,file'" if the regexp
+    contains slashes. If names are missing, the names of named capture
+    groups like "(?P<total>\d+)" are used, otherwise the header of the
+    source column followed by the number of the group.
+
+    The new columns replace the source column, use --split-keep to keep it.
+    Cells not matching the regexp result in empty cells. Splits are applied
+    before computed columns and filters, so the new columns can be used
+    everywhere:
+
+        kubectl get pods | tablizer --split 'ready=|(\d+)/(\d+)|num,total' \
+          --add 'pct = num / total * 100' -E 'pct < 100'
+
+  MERGING COLUMNS
+    The opposite of splitting is possible with the option --merge, which
+    takes the name of a new column and a template. Within the template,
+    columns are referenced by header name or number enclosed in curly
+    braces:
+
+        kubectl get pods -A | tablizer --merge 'ref={namespace}/{name}' -c ref -H
+        kube-system/coredns-5d78c9869d-7xkqm
+        monitoring/grafana-fcc54cbc9-bk7s8
+
+    The merged column is appended to the table. With --merge-remove the
+    source columns are removed and the merged column takes the place of the
+    first of them. If a column with the given name already exists, its
+    values are replaced. Merges are applied after splits and computed
+    columns, so they can be referenced in templates.
 
   INTERACTIVE FILTERING
     You can also use the interactive mode, enabled with "-I" to filter and
@@ -262,6 +738,11 @@ DESCRIPTION
     "ENTER". Use "SPACE" to select/deselect rows, use "a" to select all
     (visible) rows.
 
+    Hit "s" to sort the selected column according to its type, "S" to sort
+    it alphanumerically or use "n", "t" or "d" to sort it numerically, by
+    time or by duration. The type of the selected column is shown in the
+    footer.
+
     Commit your selection with "q". The selected rows are being fed to the
     requested output mode as usual. Abort with "CTRL-c", in which case the
     results of the interactive mode are being ignored and all rows are being
@@ -302,17 +783,45 @@ DESCRIPTION
     You can manipulate field contents using regular expressions. You have to
     tell tablizer which field[s] to operate on using the option "-T" and the
     search/replace pattern using "-R". The number of columns and patterns
-    must match.
+    must match, unless "-T" specifies only one column, in which case all
+    patterns are applied to it one after another.
 
     A search/replace pattern consists of the following elements:
 
         /search-regexp/replace-string/
 
-    The separator can be any valid character. Especially if you want to use
-    a regexp containing the "/" character, eg:
+    The separator can be any non alphanumeric character. Especially if you
+    want to use a regexp containing the "/" character, eg:
 
         |search-regexp|replace-string|
 
+    Instead of a pattern you can also use one of the functions "upper",
+    "lower", "trim" or truncate(n), which cuts values after "n" characters.
+
+    A pattern or function can be prefixed with a column specification
+    followed by a colon, in which case it applies to the matching columns
+    instead of the ones given with "-T". The column specification works like
+    with "-c", so regexps can be used to address multiple columns. If
+    multiple patterns apply to the same column, they are chained in the
+    order given:
+
+        kubectl get pods | tablizer -R 'name:/-[a-z0-9]+-[a-z0-9]+$//' -R 'name:upper' -R 'status:truncate(3)'
+
+    A pattern or function can also be restricted to certain rows by
+    appending "if" and a field filter as used with -F, including comparisons
+    and OR-groups:
+
+        kubectl get pods | tablizer -R 'age:/^.+$/stale/ if status=Completed'
+        kubectl get pods | tablizer -R 'name:upper if restarts>10|status!=Running'
+
+    The text after "if" is only treated as condition if the part before it
+    is a complete pattern or function, so "/x/ok if y/" still replaces "x"
+    by "ok if y".
+
+    Conditions are always matched against the row as it was before applying
+    any pattern. A condition referring to a column which does not exist is
+    an error.
+
     Example:
 
         cat t/testtable2
@@ -364,6 +873,19 @@ DESCRIPTION
     markdown which prints a Markdown table, yaml, which prints yaml encoding
     and CSV mode, which prints a comma separated value file.
 
+    Wide tables with only a few rows can also be rotated using --rotate,
+    which works with every output mode. The headers then become the first
+    column named "FIELD" and every row becomes a column named by its number:
+
+        kubectl get pods | tablizer --rotate -c name,status,restarts
+        FIELD      1                           2
+        NAME       repldepl-7bcd8d5b64-7zq4l   repldepl-7bcd8d5b64-m48n8
+        STATUS     Running                     Running
+        RESTARTS   1                           9
+
+    Rotation is done right before printing, that is after sorting and column
+    selection.
+
   PUT FIELDS TO CLIPBOARD
     You can let tablizer put fields to the clipboard using the option "-y".
     This best fits the use-case when the result of your filtering yields
@@ -448,6 +970,19 @@ CONFIGURATION AND COLORS
         HighlightHdrBG = "red"
         HighlightHdrFG = "white"
 
+    Frequently used filters can be put into named filter sets, which can
+    then be used with --use-filter name, eg:
+
+        filter "broken" {
+          patterns    = ["/prod/i"]
+          filters     = ["status=Error|status=CrashLoop"]
+          expressions = ["restarts > 5"]
+        }
+
+    All entries are optional. They are added to the patterns, filters and
+    expressions given on the commandline. The option --use-filter can be
+    specified multiple times.
+
     The following color definitions are available:
 
     black, blue, cyan, darkGray, default, green, lightBlue, lightCyan,
@@ -515,13 +1050,51 @@ Operational Flags:
   -s, --separator <string>           Custom field separator (maybe char, string or :class:)
   -k, --sort-by <int|name>           Sort by column (default: 1)
   -z, --fuzzy                        Use fuzzy search [experimental]
-  -F, --filter <field[!]=reg>        Filter given field with regex, can be used multiple times
+      --fuzzy-rank                   Sort rows by fuzzy rank, implies -z
+  -F, --filter <field[!]=reg>        Filter given field with regex or <,>,<=,>=, can be used multiple times
+  -E, --expr <expression>            Filter rows by expression, can be used multiple times
+      --add <name=expression>        Add a computed column, can be used multiple times
+      --fill-down <cols>             Fill empty cells with the last non-empty value above
+      --rename <col=name>            Rename a column, can be used multiple times
+      --header-transform <how>       Transform headers: lower|upper|snake|/regex/replace/
+      --map <col=file[:key:val]>     Replace values by the ones looked up in a dictionary
+      --map-add                      Add looked up values as new column instead
+      --map-default <value>          Value used for values not found in the dictionary
+      --split <col=/regex/names>     Split a column into new ones using capture groups
+      --split-keep                   Keep the source column of --split
+      --merge <name=template>        Merge columns into a new one, eg 'ref={namespace}/{name}'
+      --merge-remove                 Remove the source columns of --merge
+      --use-filter <name>            Use a filter set defined in the config file
+      --in <col=file[:keycol]>       Only show rows whose col value appears in file
+      --not-in <col=file[:keycol]>   Only show rows whose col value doesn't appear in file
+      --any                          Show rows matching any positional pattern instead of all
+      --pattern-columns <cols>       Match patterns only against the given columns
+      --before-context <n>           Show n rows before each pattern match
+      --after-context <n>            Show n rows after each pattern match
+      --context <n>                  Show n rows before and after each pattern match
   -T, --transpose-columns string     Transpose the speficied columns (separated by ,)
-  -R, --regex-transposer </from/to/> Apply /search/replace/ regexp to fields given in -T
+  -R, --regex-transposer </from/to/> Apply /search/replace/ regexp or function to fields given in -T
+                                     or to columns given as prefix (col:/from/to/)
   -j, --json                         Read JSON input (must be array of hashes)
   -I, --interactive                  Interactively filter and select rows
   -g, --auto-headers                 Generate headers if there are none present in input
   -x, --custom-headers a,b,...       Use custom headers, separated by comma
+      --join <file>                  Join input with the table in file
+      --on <col[=col]>               Key columns to join on
+      --join-type <type>             Join type: inner|left|full (default: inner)
+      --join-prefix <prefix>         Prefix for colliding headers (default: right_)
+      --group-by <cols>              Group rows by the given columns
+      --agg <aggregations>           Aggregations per group, eg 'count,sum(restarts)'
+      --footer <aggregations>        Add a footer row, eg 'sum(restarts),count(name)'
+      --stats                        Print statistics per column instead of the table
+      --stats-top <n>                Number of most frequent values (default: 3)
+      --pivot <rowcols:col>          Pivot table, rows by rowcols, columns by col
+      --pivot-agg <aggregation>      Aggregation used for pivot cells (default: count)
+      --pivot-fill <value>           Value used for empty pivot cells
+      --unpivot <cols>               Turn all other columns into key/value rows
+      --types <col=type>             Override the inferred type of a column
+      --on-error <policy>            What to do with malformed rows: fail|skip|pad|merge
+      --lenient                      Report and skip malformed rows (--on-error=skip)
 
 Output Flags (mutually exclusive):
   -X, --extended                     Enable extended output
@@ -536,12 +1109,24 @@ Output Flags (mutually exclusive):
   -o, --ofs <char>                   Output field separator, used by -A and -C. 
   -y, --yank-columns                 Yank specified columns (separated by ,) to clipboard,
                                      space separated
+      --rotate                       Rotate table, rows become columns
 
 Sort Mode Flags (mutually exclusive):
   -a, --sort-age                     sort according to age (duration) string
   -D, --sort-desc                    Sort in descending order (default: ascending)
   -i, --sort-numeric                 sort according to string numerical value
   -t, --sort-time                    sort according to time string
+      --sort-string                  sort alphanumerically instead of by column type
+
+Row Limit Flags:
+      --head <n>                     Only show the first n rows
+      --tail <n>                     Only show the last n rows
+      --offset <n>                   Skip the first n rows
+      --sample <n>                   Only show n randomly selected rows
+      --seed <n>                     Random seed used by --sample
+      --unique <cols>                Only show one row per unique value of cols
+      --unique-last                  Keep the last row per value instead of the first
+      --unique-count                 Add a COUNT column with the number of rows per value
 
 Other Flags:
   -r  --read-file <file>             Use <file> as input instead of STDIN
@@ -0,0 +1,300 @@
+/*
+Copyright © 2025 Thomas von Dein
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package lib
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tlinden/tablizer/cfg"
+)
+
+// binary size units used to format sizes
+var sizeSuffixes = []string{"B", "Ki", "Mi", "Gi", "Ti", "Pi", "Ei"}
+
+// an aggregation with its column resolved
+type aggregator struct {
+	agg    cfg.Aggregation
+	column int // 0-based, -1 for count
+	kind   int // type of the source column
+}
+
+/*
+Group  rows  by the  --group-by  columns  and  aggregate them  using
+--agg. The result is a new table containing the group columns and one
+column per aggregation, with one row per group in the order the groups
+appear in the input. Without --group-by all rows form one group.
+*/
+func GroupRows(conf cfg.Config, data *Tabdata) (*Tabdata, bool, error) {
+	if len(conf.Aggregations) == 0 {
+		return nil, false, nil
+	}
+
+	groupcols, err := PrepareColumnVars(conf.GroupBy, data)
+	if err != nil {
+		return nil, false, err
+	}
+
+	for _, col := range groupcols {
+		if col < 1 || col > len(data.headers) {
+			return nil, false, fmt.Errorf("group column %d does not exist", col)
+		}
+	}
+
+	aggregators, err := prepareAggregators(conf.Aggregations, data)
+	if err != nil {
+		return nil, false, err
+	}
+
+	newdata := Tabdata{malformed: data.malformed}
+
+	for _, col := range groupcols {
+		newdata.headers = append(newdata.headers, data.headers[col-1])
+		newdata.types = append(newdata.types, data.columnType(col-1))
+	}
+
+	for _, aggr := range aggregators {
+		newdata.headers = append(newdata.headers, aggr.agg.Header)
+		newdata.types = append(newdata.types, aggr.resultType())
+	}
+
+	for _, header := range newdata.headers {
+		newdata.maxwidthHeader = max(newdata.maxwidthHeader, len(header))
+	}
+
+	newdata.columns = len(newdata.headers)
+
+	// collect the rows of every group
+	groups := map[string][][]string{}
+	keys := []string{}
+
+	for _, row := range data.entries {
+		values := make([]string, len(groupcols))
+		for idx, col := range groupcols {
+			values[idx] = cell(row, col-1)
+		}
+
+		key := strings.Join(values, "\x00")
+		if _, exists := groups[key]; !exists {
+			keys = append(keys, key)
+		}
+
+		groups[key] = append(groups[key], row)
+	}
+
+	for _, key := range keys {
+		rows := groups[key]
+
+		newrow := []string{}
+		for _, col := range groupcols {
+			newrow = append(newrow, cell(rows[0], col-1))
+		}
+
+		for _, aggr := range aggregators {
+			value, err := aggr.aggregate(rows)
+			if err != nil {
+				return nil, false, err
+			}
+
+			newrow = append(newrow, value)
+		}
+
+		newdata.entries = append(newdata.entries, newrow)
+	}
+
+	return &newdata, true, nil
+}
+
+// resolve the columns of all aggregations and check their types
+func prepareAggregators(aggregations []cfg.Aggregation, data *Tabdata) ([]aggregator, error) {
+	aggregators := []aggregator{}
+
+	for _, agg := range aggregations {
+		aggr := aggregator{agg: agg, column: -1}
+
+		if agg.Column != "" {
+			columns, err := PrepareColumnVars(agg.Column, data)
+			if err != nil {
+				return nil, err
+			}
+
+			if len(columns) != 1 || columns[0] < 1 || columns[0] > len(data.headers) {
+				return nil, fmt.Errorf("aggregation %s must refer to exactly one column", agg.Header)
+			}
+
+			aggr.column = columns[0] - 1
+			aggr.kind = data.columnType(aggr.column)
+		}
+
+		if agg.Func == "sum" || agg.Func == "avg" {
+			switch aggr.kind {
+			case cfg.TypeInt, cfg.TypeFloat, cfg.TypeBool, cfg.TypeDuration, cfg.TypeSize:
+			default:
+				return nil, fmt.Errorf("cannot calculate %s of column %s with type %s",
+					agg.Func, agg.Column, cfg.TypeName(aggr.kind))
+			}
+		}
+
+		aggregators = append(aggregators, aggr)
+	}
+
+	return aggregators, nil
+}
+
+// the type of the aggregated column
+func (aggr aggregator) resultType() int {
+	switch aggr.agg.Func {
+	case "count":
+		return cfg.TypeInt
+	case "sum":
+		if aggr.kind == cfg.TypeBool {
+			return cfg.TypeInt
+		}
+	case "avg":
+		if aggr.kind != cfg.TypeDuration && aggr.kind != cfg.TypeSize {
+			return cfg.TypeFloat
+		}
+	}
+
+	return aggr.kind
+}
+
+// aggregate the rows of a group, empty values are ignored
+func (aggr aggregator) aggregate(rows [][]string) (string, error) {
+	if aggr.column < 0 {
+		return strconv.Itoa(len(rows)), nil
+	}
+
+	values := []string{}
+
+	for _, row := range rows {
+		value := strings.TrimSpace(cell(row, aggr.column))
+		if !isNull(value) {
+			values = append(values, value)
+		}
+	}
+
+	switch aggr.agg.Func {
+	case "count":
+		return strconv.Itoa(len(values)), nil
+	case "min", "max":
+		if len(values) == 0 {
+			return "", nil
+		}
+
+		result := values[0]
+
+		for _, value := range values[1:] {
+			cmp := compareValues(aggr.kind, value, result)
+			if (aggr.agg.Func == "min" && cmp < 0) || (aggr.agg.Func == "max" && cmp > 0) {
+				result = value
+			}
+		}
+
+		return result, nil
+	}
+
+	// sum or avg
+	sum := 0.0
+
+	for _, value := range values {
+		number, ok := aggregateNumber(aggr.kind, value)
+		if !ok {
+			return "", fmt.Errorf("cannot convert value %s of column %s to %s",
+				value, aggr.agg.Column, cfg.TypeName(aggr.kind))
+		}
+
+		sum += number
+	}
+
+	if aggr.agg.Func == "avg" {
+		if len(values) == 0 {
+			return "", nil
+		}
+
+		sum /= float64(len(values))
+	}
+
+	return formatNumber(aggr.resultType(), sum), nil
+}
+
+// convert a value to a number for sum and avg, durations are lenient
+func aggregateNumber(kind int, value string) (float64, bool) {
+	if kind == cfg.TypeDuration {
+		return duration2float(value), true
+	}
+
+	return toNumber(kind, value)
+}
+
+// format a number according to the given type, the opposite of toNumber()
+func formatNumber(kind int, number float64) string {
+	switch kind {
+	case cfg.TypeInt:
+		return strconv.FormatInt(int64(math.Round(number)), 10)
+	case cfg.TypeDuration:
+		return int2duration(int(math.Round(number)))
+	case cfg.TypeSize:
+		return size2string(number)
+	case cfg.TypeTime:
+		// timestamps without zone are parsed as UTC
+		return time.Unix(int64(math.Round(number)), 0).UTC().Format(time.DateTime)
+	default:
+		return strconv.FormatFloat(number, 'f', -1, 64)
+	}
+}
+
+// convert seconds into a duration like 1d2h3m4s, the opposite of
+// duration2int()
+func int2duration(seconds int) string {
+	switch {
+	case seconds == 0:
+		return "0s"
+	case seconds < 0:
+		return "-" + int2duration(-seconds)
+	}
+
+	var duration strings.Builder
+
+	for _, unit := range []struct {
+		suffix  string
+		seconds int
+	}{{"d", 86400}, {"h", 3600}, {"m", 60}, {"s", 1}} {
+		if seconds >= unit.seconds {
+			fmt.Fprintf(&duration, "%d%s", seconds/unit.seconds, unit.suffix)
+			seconds %= unit.seconds
+		}
+	}
+
+	return duration.String()
+}
+
+// convert bytes into a size using binary units like 1.5Gi
+func size2string(size float64) string {
+	unit := 0
+
+	for size >= 1024 && unit < len(sizeSuffixes)-1 {
+		size /= 1024
+		unit++
+	}
+
+	return strconv.FormatFloat(math.Round(size*10)/10, 'f', -1, 64) + sizeSuffixes[unit]
+}
@@ -0,0 +1,141 @@
+/*
+Copyright © 2025 Thomas von Dein
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package lib
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tlinden/tablizer/cfg"
+)
+
+func newAggData() Tabdata {
+	data := Tabdata{
+		headers: []string{"NAME", "STATUS", "RESTARTS", "CPU", "AGE", "MEM"},
+		entries: [][]string{
+			{"a", "Running", "1", "0.5", "1h", "512Mi"},
+			{"b", "Error", "10", "0.25", "2d", "1Gi"},
+			{"c", "Running", "3", "1.25", "30m", "1Gi"},
+			{"d", "Running", "", "0.75", "1d", "512Mi"},
+		},
+	}
+
+	inferTypes(&data)
+
+	return data
+}
+
+func TestGroupRows(t *testing.T) {
+	var tests = []struct {
+		groupby string
+		agg     string
+		headers []string
+		expect  [][]string
+	}{
+		{
+			"status", "",
+			[]string{"STATUS", "count"},
+			[][]string{{"Running", "3"}, {"Error", "1"}},
+		},
+		{
+			"status", "count,sum(restarts),avg(cpu),max(age)",
+			[]string{"STATUS", "count", "sum_restarts", "avg_cpu", "max_age"},
+			[][]string{{"Running", "3", "4", "0.8333333333333334", "1d"}, {"Error", "1", "10", "0.25", "2d"}},
+		},
+		{
+			"status", "sum(age),sum(mem),min(name),count(restarts)",
+			[]string{"STATUS", "sum_age", "sum_mem", "min_name", "count_restarts"},
+			[][]string{{"Running", "1d1h30m", "2Gi", "a", "2"}, {"Error", "2d", "1Gi", "b", "1"}},
+		},
+		{
+			"", "count,avg(restarts)",
+			[]string{"count", "avg_restarts"},
+			[][]string{{"4", "4.666666666666667"}},
+		},
+	}
+
+	for _, testdata := range tests {
+		testname := fmt.Sprintf("group-rows-%s-%s", testdata.groupby, testdata.agg)
+
+		t.Run(testname, func(t *testing.T) {
+			data := newAggData()
+			conf := cfg.Config{GroupBy: testdata.groupby, RawAgg: testdata.agg}
+
+			assert.NoError(t, conf.PrepareAggregations())
+
+			newdata, changed, err := GroupRows(conf, &data)
+			assert.NoError(t, err)
+			assert.True(t, changed)
+
+			assert.EqualValues(t, testdata.headers, newdata.headers)
+			assert.EqualValues(t, testdata.expect, newdata.entries)
+			assert.EqualValues(t, len(newdata.headers), len(newdata.types))
+		})
+	}
+}
+
+func TestGroupRowsErrors(t *testing.T) {
+	var tests = []struct {
+		groupby string
+		agg     string
+	}{
+		{"status", "sum(name)"},
+		{"status", "avg(nothere)"},
+		{"99", "count"},
+	}
+
+	for _, testdata := range tests {
+		testname := fmt.Sprintf("group-rows-error-%s-%s", testdata.groupby, testdata.agg)
+
+		t.Run(testname, func(t *testing.T) {
+			data := newAggData()
+			conf := cfg.Config{GroupBy: testdata.groupby, RawAgg: testdata.agg}
+
+			assert.NoError(t, conf.PrepareAggregations())
+
+			_, _, err := GroupRows(conf, &data)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestFormatNumber(t *testing.T) {
+	var tests = []struct {
+		kind   int
+		number float64
+		expect string
+	}{
+		{cfg.TypeInt, 42, "42"},
+		{cfg.TypeFloat, 2.5, "2.5"},
+		{cfg.TypeFloat, 0.125, "0.125"},
+		{cfg.TypeTime, 1735732800, "2025-01-01 12:00:00"},
+		{cfg.TypeDuration, 93784, "1d2h3m4s"},
+		{cfg.TypeDuration, 0, "0s"},
+		{cfg.TypeSize, 1536 * 1024 * 1024, "1.5Gi"},
+		{cfg.TypeSize, 100, "100B"},
+	}
+
+	for _, testdata := range tests {
+		testname := fmt.Sprintf("format-number-%s-%f", cfg.TypeName(testdata.kind), testdata.number)
+
+		t.Run(testname, func(t *testing.T) {
+			assert.EqualValues(t, testdata.expect, formatNumber(testdata.kind, testdata.number))
+		})
+	}
+}
@@ -22,7 +22,12 @@ type Tabdata struct {
 	maxwidthHeader int      // longest header
 	columns        int      // count
 	headers        []string // [ "ID", "NAME", ...]
+	types          []int    // [ cfg.TypeInt, cfg.TypeString, ...]
 	entries        [][]string
+	context        []bool        // marks context rows, aligned with entries, nil if none
+	malformed      int           // number of malformed input rows
+	footer         []aggregator  // --footer aggregations
+	footerValues   []footerValue // calculated footer aggregations
 }
 
 func (data *Tabdata) CloneEmpty() Tabdata {
@@ -30,7 +35,31 @@ func (data *Tabdata) CloneEmpty() Tabdata {
 		maxwidthHeader: data.maxwidthHeader,
 		columns:        data.columns,
 		headers:        data.headers,
+		types:          data.types,
+		malformed:      data.malformed,
 	}
 
 	return newdata
 }
+
+// Reorder  the rows  and  their  context marks  by  the given  indices
+// into entries, rows not contained in order are being removed
+func (data *Tabdata) reorderRows(order []int) {
+	entries := make([][]string, len(order))
+
+	var context []bool
+	if data.context != nil {
+		context = make([]bool, len(order))
+	}
+
+	for idx, pos := range order {
+		entries[idx] = data.entries[pos]
+
+		if context != nil {
+			context[idx] = data.context[pos]
+		}
+	}
+
+	data.entries = entries
+	data.context = context
+}
@@ -0,0 +1,78 @@
+/*
+Copyright © 2025 Thomas von Dein
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package lib
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/tlinden/tablizer/cfg"
+)
+
+/*
+Add the columns given with --add name=expression. Every expression
+is evaluated per row and may refer to any column including the ones
+computed before. If a column with the given name already exists, its
+values will be replaced. The type of new columns is inferred from the
+computed values.
+*/
+func AddColumns(conf cfg.Config, data *Tabdata) (*Tabdata, bool, error) {
+	if len(conf.Computed) == 0 {
+		return nil, false, nil
+	}
+
+	newdata := data.CloneEmpty()
+	newdata.headers = slices.Clone(data.headers)
+	newdata.types = slices.Clone(data.types)
+	newdata.entries = make([][]string, len(data.entries))
+
+	for idx, row := range data.entries {
+		newdata.entries[idx] = slices.Clone(row)
+	}
+
+	for _, computed := range conf.Computed {
+		expr, err := CompileExpression(computed.Expression, &newdata)
+		if err != nil {
+			return nil, false, err
+		}
+
+		col := slices.IndexFunc(newdata.headers, func(header string) bool {
+			return strings.EqualFold(header, computed.Name)
+		})
+
+		if col < 0 {
+			addColumn(&conf, &newdata, computed.Name, cfg.TypeString)
+			col = len(newdata.headers) - 1
+		}
+
+		for idx, row := range newdata.entries {
+			for len(row) <= col {
+				row = append(row, "")
+			}
+
+			row[col] = expr.Eval(row)
+			newdata.entries[idx] = row
+		}
+
+		if err := reinferType(conf, &newdata, col); err != nil {
+			return nil, false, err
+		}
+	}
+
+	return &newdata, true, nil
+}
@@ -0,0 +1,73 @@
+/*
+Copyright © 2025 Thomas von Dein
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package lib
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tlinden/tablizer/cfg"
+)
+
+func TestAddColumns(t *testing.T) {
+	data := newExprData()
+
+	conf := cfg.Config{Computed: []cfg.Computed{
+		{Name: "age_s", Expression: "seconds(age)"},
+		{Name: "age_h", Expression: "age_s / 3600"},
+		{Name: "status", Expression: "lower(status)"},
+	}}
+
+	newdata, changed, err := AddColumns(conf, &data)
+
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	assert.EqualValues(t, []string{"NAME", "STATUS", "RESTARTS", "AGE", "CPU(cores)", "age_s", "age_h"},
+		newdata.headers)
+	assert.EqualValues(t, []string{"alpha", "running", "0", "11d", "0.5", "950400", "264"},
+		newdata.entries[0])
+	assert.EqualValues(t, []string{"gamma", "completed", "7", "2h", "1.25", "7200", "2"},
+		newdata.entries[2])
+	assert.EqualValues(t, cfg.TypeInt, newdata.types[5])
+	assert.EqualValues(t, cfg.TypeFloat, newdata.types[6])
+
+	// the original data must not be modified
+	assert.EqualValues(t, "Running", data.entries[0][1])
+	assert.EqualValues(t, 5, len(data.headers))
+
+	// types given with --types are retained
+	conf.Types = map[string]int{"age_s": cfg.TypeString}
+	newdata, _, err = AddColumns(conf, &data)
+
+	assert.NoError(t, err)
+	assert.EqualValues(t, cfg.TypeString, newdata.types[5])
+
+	// durations may become negative
+	conf.Types = nil
+	conf.Computed = []cfg.Computed{{Name: "left", Expression: "age - 2d"}}
+	newdata, _, err = AddColumns(conf, &data)
+
+	assert.NoError(t, err)
+	assert.EqualValues(t, []string{"9d", "-1d23h15m", "-1d22h"}, columnValues(newdata, 5))
+	assert.EqualValues(t, cfg.TypeDuration, newdata.types[5])
+
+	conf.Computed = []cfg.Computed{{Name: "x", Expression: "nonexistent + 1"}}
+	_, _, err = AddColumns(conf, &data)
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,862 @@
+/*
+Copyright © 2025 Thomas von Dein
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package lib
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/tlinden/tablizer/cfg"
+)
+
+/*
+A small expression language used by -E and --add, e.g.:
+
+	restarts > 5 && status != "Running" || age < 1h
+
+Columns can be referenced by name (case insensitive), by number
+($3) or by arbitrary header names (${CPU(cores)}). Comparisons are
+performed according to the column type, so durations, sizes and
+timestamps can be compared with literals like 1h, 5G or "2024-01-01".
+*/
+
+const (
+	tokEOF = iota
+	tokNumber
+	tokUnit // number with unit: 1h, 5G
+	tokString
+	tokIdent
+	tokColumn // $3 or ${name}
+	tokOp
+)
+
+type token struct {
+	kind int
+	text string
+	pos  int
+}
+
+// a typed value, literals adopt the type of the column they are
+// being compared with
+type exprValue struct {
+	str     string
+	kind    int
+	literal bool
+}
+
+// nodes of the syntax tree
+type exprNode interface {
+	eval(row []string) exprValue
+}
+
+// A compiled expression, ready to be evaluated against data rows
+type Expression struct {
+	source string
+	root   exprNode
+}
+
+type exprParser struct {
+	tokens []token
+	pos    int
+	data   *Tabdata
+}
+
+var (
+	exprOperators = []string{
+		"&&", "||", "==", "!=", "<=", ">=", "=~", "!~",
+		"<", ">", "=", "!", "(", ")", ",",
+		"+", "-", "*", "/", "%",
+	}
+
+	exprTrue  = exprValue{str: "true", kind: cfg.TypeBool}
+	exprFalse = exprValue{str: "false", kind: cfg.TypeBool}
+)
+
+// Parse an expression and resolve column references using the
+// headers of data
+func CompileExpression(source string, data *Tabdata) (*Expression, error) {
+	tokens, err := tokenize(source)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression %q: %w", source, err)
+	}
+
+	parser := &exprParser{tokens: tokens, data: data}
+
+	root, err := parser.parseOr()
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression %q: %w", source, err)
+	}
+
+	if parser.peek().kind != tokEOF {
+		return nil, fmt.Errorf("invalid expression %q: unexpected %q at position %d",
+			source, parser.peek().text, parser.peek().pos)
+	}
+
+	return &Expression{source: source, root: root}, nil
+}
+
+// evaluate the expression against a row, returns the result as bool
+func (expr *Expression) Match(row []string) bool {
+	return expr.root.eval(row).truthy()
+}
+
+// evaluate the expression against a row, returns the resulting value,
+// used by computed columns
+func (expr *Expression) Eval(row []string) string {
+	return expr.root.eval(row).str
+}
+
+func tokenize(source string) ([]token, error) {
+	tokens := []token{}
+	runes := []rune(source)
+	pos := 0
+
+	for pos < len(runes) {
+		char := runes[pos]
+		start := pos
+
+		switch {
+		case unicode.IsSpace(char):
+			pos++
+
+		case unicode.IsDigit(char) || (char == '.' && pos+1 < len(runes) && unicode.IsDigit(runes[pos+1])):
+			for pos < len(runes) && (unicode.IsDigit(runes[pos]) || runes[pos] == '.') {
+				pos++
+			}
+
+			kind := tokNumber
+
+			// numbers followed by letters are durations or sizes
+			for pos < len(runes) && (unicode.IsLetter(runes[pos]) || unicode.IsDigit(runes[pos])) {
+				kind = tokUnit
+				pos++
+			}
+
+			tokens = append(tokens, token{kind: kind, text: string(runes[start:pos]), pos: start})
+
+		case char == '"' || char == '\'':
+			pos++
+			str := strings.Builder{}
+
+			for pos < len(runes) && runes[pos] != char {
+				if runes[pos] == '\\' && pos+1 < len(runes) {
+					pos++
+				}
+
+				str.WriteRune(runes[pos])
+				pos++
+			}
+
+			if pos >= len(runes) {
+				return nil, fmt.Errorf("unterminated string at position %d", start)
+			}
+
+			pos++
+			tokens = append(tokens, token{kind: tokString, text: str.String(), pos: start})
+
+		case char == '$':
+			pos++
+
+			if pos < len(runes) && runes[pos] == '{' {
+				end := pos
+				for end < len(runes) && runes[end] != '}' {
+					end++
+				}
+
+				if end >= len(runes) {
+					return nil, fmt.Errorf("unterminated column reference at position %d", start)
+				}
+
+				tokens = append(tokens, token{kind: tokColumn, text: string(runes[pos+1 : end]), pos: start})
+				pos = end + 1
+			} else {
+				for pos < len(runes) && unicode.IsDigit(runes[pos]) {
+					pos++
+				}
+
+				if pos == start+1 {
+					return nil, fmt.Errorf("invalid column reference at position %d", start)
+				}
+
+				tokens = append(tokens, token{kind: tokColumn, text: string(runes[start+1 : pos]), pos: start})
+			}
+
+		case unicode.IsLetter(char) || char == '_':
+			for pos < len(runes) && (unicode.IsLetter(runes[pos]) || unicode.IsDigit(runes[pos]) || runes[pos] == '_') {
+				pos++
+			}
+
+			tokens = append(tokens, token{kind: tokIdent, text: string(runes[start:pos]), pos: start})
+
+		default:
+			found := false
+
+			for _, op := range exprOperators {
+				if strings.HasPrefix(string(runes[pos:]), op) {
+					tokens = append(tokens, token{kind: tokOp, text: op, pos: start})
+					pos += len(op)
+					found = true
+
+					break
+				}
+			}
+
+			if !found {
+				return nil, fmt.Errorf("unexpected character %q at position %d", char, pos)
+			}
+		}
+	}
+
+	return append(tokens, token{kind: tokEOF, pos: len(runes)}), nil
+}
+
+func (p *exprParser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *exprParser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+
+	return tok
+}
+
+// check if the next token is one of the given operators or keywords,
+// consume it if so
+func (p *exprParser) accept(ops ...string) (string, bool) {
+	tok := p.peek()
+
+	if tok.kind != tokOp && tok.kind != tokIdent {
+		return "", false
+	}
+
+	for _, op := range ops {
+		if (tok.kind == tokOp && tok.text == op) ||
+			(tok.kind == tokIdent && strings.EqualFold(tok.text, op)) {
+			p.next()
+
+			return op, true
+		}
+	}
+
+	return "", false
+}
+
+func (p *exprParser) expect(op string) error {
+	if _, ok := p.accept(op); !ok {
+		return fmt.Errorf("expected %q at position %d", op, p.peek().pos)
+	}
+
+	return nil
+}
+
+func (p *exprParser) parseOr() (exprNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		if _, ok := p.accept("||", "or"); !ok {
+			return left, nil
+		}
+
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+
+		left = &logicalNode{op: "||", left: left, right: right}
+	}
+}
+
+func (p *exprParser) parseAnd() (exprNode, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		if _, ok := p.accept("&&", "and"); !ok {
+			return left, nil
+		}
+
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+
+		left = &logicalNode{op: "&&", left: left, right: right}
+	}
+}
+
+func (p *exprParser) parseNot() (exprNode, error) {
+	if _, ok := p.accept("!", "not"); ok {
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+
+		return &notNode{operand: operand}, nil
+	}
+
+	return p.parseComparison()
+}
+
+func (p *exprParser) parseComparison() (exprNode, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	op, ok := p.accept("==", "=", "!=", "<=", ">=", "<", ">", "=~", "!~", "contains", "matches", "in")
+	if !ok {
+		return left, nil
+	}
+
+	switch op {
+	case "in":
+		list, err := p.parseList()
+		if err != nil {
+			return nil, err
+		}
+
+		return &inNode{left: left, list: list}, nil
+	case "=~", "!~", "matches":
+		tok := p.next()
+		if tok.kind != tokString {
+			return nil, fmt.Errorf("expected regexp string at position %d", tok.pos)
+		}
+
+		re, err := regexp.Compile(tok.text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regexp %q: %w", tok.text, err)
+		}
+
+		return &matchNode{left: left, re: re, negate: op == "!~"}, nil
+	}
+
+	right, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	if op == "=" {
+		op = "=="
+	}
+
+	return &compareNode{op: op, left: left, right: right}, nil
+}
+
+func (p *exprParser) parseList() ([]exprNode, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+
+	list := []exprNode{}
+
+	for {
+		item, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+
+		list = append(list, item)
+
+		if _, ok := p.accept(","); !ok {
+			break
+		}
+	}
+
+	return list, p.expect(")")
+}
+
+// operands may be arithmetic expressions, + and - bind weaker than *,
+// / and %
+func (p *exprParser) parseOperand() (exprNode, error) {
+	left, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		op, ok := p.accept("+", "-")
+		if !ok {
+			return left, nil
+		}
+
+		right, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+
+		left = &arithNode{op: op, left: left, right: right}
+	}
+}
+
+func (p *exprParser) parseTerm() (exprNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		op, ok := p.accept("*", "/", "%")
+		if !ok {
+			return left, nil
+		}
+
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		left = &arithNode{op: op, left: left, right: right}
+	}
+}
+
+func (p *exprParser) parseUnary() (exprNode, error) {
+	if _, ok := p.accept("-"); ok {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		// fold negative numbers into a literal, so that they are
+		// still treated as literals when comparing
+		if literal, ok := operand.(*literalNode); ok &&
+			(literal.value.kind == cfg.TypeInt || literal.value.kind == cfg.TypeFloat) &&
+			!strings.HasPrefix(literal.value.str, "-") {
+			literal.value.str = "-" + literal.value.str
+
+			return literal, nil
+		}
+
+		return &negateNode{operand: operand}, nil
+	}
+
+	return p.parsePrimary()
+}
+
+func (p *exprParser) parsePrimary() (exprNode, error) {
+	tok := p.next()
+
+	switch tok.kind {
+	case tokNumber:
+		kind := cfg.TypeInt
+		if strings.Contains(tok.text, ".") {
+			kind = cfg.TypeFloat
+		}
+
+		if _, err := strconv.ParseFloat(tok.text, 64); err != nil {
+			return nil, fmt.Errorf("invalid number %q at position %d", tok.text, tok.pos)
+		}
+
+		return &literalNode{value: exprValue{str: tok.text, kind: kind, literal: true}}, nil
+
+	case tokUnit:
+		for _, kind := range []int{cfg.TypeDuration, cfg.TypeSize} {
+			if isType(kind, tok.text) {
+				return &literalNode{value: exprValue{str: tok.text, kind: kind, literal: true}}, nil
+			}
+		}
+
+		return nil, fmt.Errorf("invalid duration or size %q at position %d", tok.text, tok.pos)
+
+	case tokString:
+		return &literalNode{value: exprValue{str: tok.text, kind: cfg.TypeString, literal: true}}, nil
+
+	case tokColumn:
+		return p.column(tok)
+
+	case tokIdent:
+		switch strings.ToLower(tok.text) {
+		case "true":
+			return &literalNode{value: exprValue{str: "true", kind: cfg.TypeBool, literal: true}}, nil
+		case "false":
+			return &literalNode{value: exprValue{str: "false", kind: cfg.TypeBool, literal: true}}, nil
+		}
+
+		if next := p.peek(); next.kind == tokOp && next.text == "(" {
+			return p.call(tok)
+		}
+
+		return p.column(tok)
+
+	case tokOp:
+		if tok.text == "(" {
+			node, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+
+			return node, p.expect(")")
+		}
+	}
+
+	if tok.kind == tokEOF {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+
+	return nil, fmt.Errorf("unexpected %q at position %d", tok.text, tok.pos)
+}
+
+// resolve a column reference by number or name
+func (p *exprParser) column(tok token) (exprNode, error) {
+	if num, err := strconv.Atoi(tok.text); err == nil {
+		if num < 1 || num > len(p.data.headers) {
+			return nil, fmt.Errorf("column %d at position %d does not exist", num, tok.pos)
+		}
+
+		return &columnNode{idx: num - 1, kind: p.data.columnType(num - 1)}, nil
+	}
+
+	for idx, header := range p.data.headers {
+		if strings.EqualFold(header, tok.text) {
+			return &columnNode{idx: idx, kind: p.data.columnType(idx)}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unknown column %q at position %d", tok.text, tok.pos)
+}
+
+// parse the arguments of a function call
+func (p *exprParser) call(tok token) (exprNode, error) {
+	name := strings.ToLower(tok.text)
+
+	function, ok := exprFuncs[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %q at position %d", tok.text, tok.pos)
+	}
+
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+
+	args := []exprNode{}
+
+	if _, ok := p.accept(")"); !ok {
+		for {
+			arg, err := p.parseOperand()
+			if err != nil {
+				return nil, err
+			}
+
+			args = append(args, arg)
+
+			if _, ok := p.accept(","); !ok {
+				break
+			}
+		}
+
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(args) < function.minargs || (function.maxargs >= 0 && len(args) > function.maxargs) {
+		return nil, fmt.Errorf("wrong number of arguments for function %s() at position %d",
+			name, tok.pos)
+	}
+
+	return &callNode{function: function, args: args}, nil
+}
+
+type literalNode struct {
+	value exprValue
+}
+
+func (node *literalNode) eval(_ []string) exprValue {
+	return node.value
+}
+
+type columnNode struct {
+	idx  int
+	kind int
+}
+
+func (node *columnNode) eval(row []string) exprValue {
+	if node.idx >= len(row) {
+		return exprValue{kind: node.kind}
+	}
+
+	return exprValue{str: row[node.idx], kind: node.kind}
+}
+
+type logicalNode struct {
+	op    string
+	left  exprNode
+	right exprNode
+}
+
+func (node *logicalNode) eval(row []string) exprValue {
+	left := node.left.eval(row).truthy()
+
+	switch {
+	case node.op == "&&" && !left:
+		return exprFalse
+	case node.op == "||" && left:
+		return exprTrue
+	}
+
+	return boolValue(node.right.eval(row).truthy())
+}
+
+type notNode struct {
+	operand exprNode
+}
+
+func (node *notNode) eval(row []string) exprValue {
+	return boolValue(!node.operand.eval(row).truthy())
+}
+
+type compareNode struct {
+	op    string
+	left  exprNode
+	right exprNode
+}
+
+func (node *compareNode) eval(row []string) exprValue {
+	left := node.left.eval(row)
+	right := node.right.eval(row)
+
+	if node.op == "contains" {
+		return boolValue(strings.Contains(left.str, right.str))
+	}
+
+	res, ok := compareTyped(commonKind(left, right), left.str, right.str)
+	if !ok {
+		// values can't be compared, only (in)equality can be checked
+		switch node.op {
+		case "==":
+			return boolValue(left.str == right.str)
+		case "!=":
+			return boolValue(left.str != right.str)
+		default:
+			return exprFalse
+		}
+	}
+
+	switch node.op {
+	case "==":
+		return boolValue(res == 0)
+	case "!=":
+		return boolValue(res != 0)
+	case "<":
+		return boolValue(res < 0)
+	case "<=":
+		return boolValue(res <= 0)
+	case ">":
+		return boolValue(res > 0)
+	default:
+		return boolValue(res >= 0)
+	}
+}
+
+type inNode struct {
+	left exprNode
+	list []exprNode
+}
+
+func (node *inNode) eval(row []string) exprValue {
+	left := node.left.eval(row)
+
+	for _, item := range node.list {
+		right := item.eval(row)
+
+		res, ok := compareTyped(commonKind(left, right), left.str, right.str)
+		if (ok && res == 0) || (!ok && left.str == right.str) {
+			return exprTrue
+		}
+	}
+
+	return exprFalse
+}
+
+type matchNode struct {
+	left   exprNode
+	re     *regexp.Regexp
+	negate bool
+}
+
+func (node *matchNode) eval(row []string) exprValue {
+	return boolValue(node.re.MatchString(node.left.eval(row).str) != node.negate)
+}
+
+func boolValue(value bool) exprValue {
+	if value {
+		return exprTrue
+	}
+
+	return exprFalse
+}
+
+// everything which is not empty, zero or false is true
+func (value exprValue) truthy() bool {
+	switch strings.ToLower(strings.TrimSpace(value.str)) {
+	case "", "0", "false", "no":
+		return false
+	}
+
+	return true
+}
+
+// Determine the  type to use  when comparing  two values. Column
+// types take precedence over literal types, so that "5" compared to a
+// numeric column will be compared numerically.
+func commonKind(left, right exprValue) int {
+	for _, value := range []exprValue{left, right} {
+		if !value.literal && value.kind != cfg.TypeString {
+			return value.kind
+		}
+	}
+
+	for _, value := range []exprValue{left, right} {
+		if value.kind != cfg.TypeString {
+			return value.kind
+		}
+	}
+
+	return cfg.TypeString
+}
+
+type arithNode struct {
+	op    string
+	left  exprNode
+	right exprNode
+}
+
+func (node *arithNode) eval(row []string) exprValue {
+	left := node.left.eval(row)
+	right := node.right.eval(row)
+
+	if node.op == "+" && (left.kind == cfg.TypeString || right.kind == cfg.TypeString) {
+		return exprValue{str: left.str + right.str, kind: cfg.TypeString}
+	}
+
+	kind := arithKind(node.op, left.kind, right.kind)
+
+	leftnum, leftok := left.number()
+	rightnum, rightok := right.number()
+
+	if !leftok || !rightok {
+		return exprValue{kind: kind}
+	}
+
+	var result float64
+
+	switch node.op {
+	case "+":
+		result = leftnum + rightnum
+	case "-":
+		result = leftnum - rightnum
+	case "*":
+		result = leftnum * rightnum
+	case "/":
+		if rightnum == 0 {
+			return exprValue{kind: kind}
+		}
+
+		result = leftnum / rightnum
+	case "%":
+		if rightnum == 0 {
+			return exprValue{kind: kind}
+		}
+
+		result = math.Mod(leftnum, rightnum)
+	}
+
+	return exprValue{str: formatNumber(kind, result), kind: kind}
+}
+
+type negateNode struct {
+	operand exprNode
+}
+
+func (node *negateNode) eval(row []string) exprValue {
+	value := node.operand.eval(row)
+
+	number, ok := value.number()
+	if !ok {
+		return exprValue{kind: value.kind, literal: value.literal}
+	}
+
+	return exprValue{str: formatNumber(value.kind, -number), kind: value.kind, literal: value.literal}
+}
+
+type callNode struct {
+	function exprFunc
+	args     []exprNode
+}
+
+func (node *callNode) eval(row []string) exprValue {
+	args := make([]exprValue, len(node.args))
+
+	for idx, arg := range node.args {
+		args[idx] = arg.eval(row)
+	}
+
+	return node.function.call(args)
+}
+
+/*
+Determine the type of the result of an arithmetic operation. Units
+are retained, so that age + 1h is a duration and size * 2 is a
+size. The difference of two timestamps is a duration and the ratio
+of two durations or sizes is a plain number.
+*/
+func arithKind(op string, left, right int) int {
+	hasUnit := func(kind int) bool {
+		return kind == cfg.TypeDuration || kind == cfg.TypeSize || kind == cfg.TypeTime
+	}
+
+	switch {
+	case left == cfg.TypeTime && right == cfg.TypeTime && op == "-":
+		return cfg.TypeDuration
+	case hasUnit(left) && hasUnit(right) && (op == "/" || op == "%"):
+		return cfg.TypeFloat
+	case hasUnit(left):
+		return left
+	case hasUnit(right):
+		return right
+	case left == cfg.TypeInt && right == cfg.TypeInt && op != "/":
+		return cfg.TypeInt
+	}
+
+	return cfg.TypeFloat
+}
+
+// convert a value into a number used for arithmetic, durations are
+// converted leniently, so that "35 (45m ago)" works as well
+func (value exprValue) number() (float64, bool) {
+	if isNull(value.str) {
+		return 0, false
+	}
+
+	if number, ok := aggregateNumber(value.kind, value.str); ok {
+		return number, true
+	}
+
+	return toNumber(cfg.TypeFloat, value.str)
+}
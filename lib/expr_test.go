@@ -0,0 +1,212 @@
+/*
+Copyright © 2025 Thomas von Dein
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package lib
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tlinden/tablizer/cfg"
+)
+
+func newExprData() Tabdata {
+	data := Tabdata{
+		headers: []string{"NAME", "STATUS", "RESTARTS", "AGE", "CPU(cores)"},
+		entries: [][]string{
+			{"alpha", "Running", "0", "11d", "0.5"},
+			{"beta", "CrashLoop", "35", "45m", "0.1"},
+			{"gamma", "Completed", "7", "2h", "1.25"},
+		},
+	}
+
+	inferTypes(&data)
+
+	return data
+}
+
+func TestExpressions(t *testing.T) {
+	var tests = []struct {
+		expr   string
+		expect []string // names of matching rows
+	}{
+		{`restarts > 5`, []string{"beta", "gamma"}},
+		{`restarts > 5 && status != "Running" || age < 1h`, []string{"beta", "gamma"}},
+		{`restarts > 5 && (status != "CrashLoop" || age < 1h)`, []string{"beta", "gamma"}},
+		{`restarts > 5 and not (status == "CrashLoop")`, []string{"gamma"}},
+		{`age >= 1d`, []string{"alpha"}},
+		{`$3 = 0`, []string{"alpha"}},
+		{`${CPU(cores)} < 1`, []string{"alpha", "beta"}},
+		{`status in ("Running", "Completed")`, []string{"alpha", "gamma"}},
+		{`name contains "mm"`, []string{"gamma"}},
+		{`name matches "^(a|b)"`, []string{"alpha", "beta"}},
+		{`name !~ "^(a|b)"`, []string{"gamma"}},
+		{`!(restarts < 10)`, []string{"beta"}},
+		{`restarts > -5`, []string{"alpha", "beta", "gamma"}},
+	}
+
+	for _, testdata := range tests {
+		testname := fmt.Sprintf("expression-%s", testdata.expr)
+
+		t.Run(testname, func(t *testing.T) {
+			data := newExprData()
+
+			expr, err := CompileExpression(testdata.expr, &data)
+			assert.NoError(t, err)
+
+			got := []string{}
+			for _, row := range data.entries {
+				if expr.Match(row) {
+					got = append(got, row[0])
+				}
+			}
+
+			assert.EqualValues(t, testdata.expect, got)
+		})
+	}
+}
+
+func TestExpressionEval(t *testing.T) {
+	var tests = []struct {
+		expr   string
+		expect []string // values of all rows
+	}{
+		{`restarts * 2 + 1`, []string{"1", "71", "15"}},
+		{`restarts / 2`, []string{"0", "17.5", "3.5"}},
+		{`-(restarts % 5)`, []string{"0", "0", "-2"}},
+		{`seconds(age)`, []string{"950400", "2700", "7200"}},
+		{`age + 30m`, []string{"11d30m", "1h15m", "2h30m"}},
+		{`age / 1h`, []string{"264", "0.75", "2"}},
+		{`age - 2d`, []string{"9d", "-1d23h15m", "-1d22h"}},
+		{`-age`, []string{"-11d", "-45m", "-2h"}},
+		{`duration(seconds(age) * 2)`, []string{"22d", "1h30m", "4h"}},
+		{`round(${CPU(cores)} * 3, 1)`, []string{"1.5", "0.3", "3.8"}},
+		{`upper(substr(name, 0, 2)) + "-" + lower(status)`, []string{"AL-running", "BE-crashloop", "GA-completed"}},
+		{`concat(name, "/", len(status))`, []string{"alpha/7", "beta/9", "gamma/9"}},
+		{`replace(status, "n", "N")`, []string{"RuNNiNg", "CrashLoop", "Completed"}},
+		{`restarts > 5`, []string{"false", "true", "true"}},
+		{`bytes("1Ki") * restarts`, []string{"0", "35840", "7168"}},
+		{`size(restarts * 1024)`, []string{"0B", "35Ki", "7Ki"}},
+	}
+
+	for _, testdata := range tests {
+		testname := fmt.Sprintf("eval-%s", testdata.expr)
+
+		t.Run(testname, func(t *testing.T) {
+			data := newExprData()
+
+			expr, err := CompileExpression(testdata.expr, &data)
+			assert.NoError(t, err)
+
+			got := []string{}
+			for _, row := range data.entries {
+				got = append(got, expr.Eval(row))
+			}
+
+			assert.EqualValues(t, testdata.expect, got)
+		})
+	}
+}
+
+func TestNegativeLiterals(t *testing.T) {
+	var tests = []struct {
+		expr   string
+		expect string
+	}{
+		{`-5`, "-5"},
+		{`-0.5`, "-0.5"},
+		{`-(5)`, "-5"},
+		{`--5`, "5"},
+	}
+
+	for _, testdata := range tests {
+		testname := fmt.Sprintf("negative-literal-%s", testdata.expr)
+
+		t.Run(testname, func(t *testing.T) {
+			data := newExprData()
+
+			expr, err := CompileExpression(testdata.expr, &data)
+			assert.NoError(t, err)
+
+			value := expr.root.eval(nil)
+			assert.EqualValues(t, testdata.expect, value.str)
+			assert.True(t, value.literal)
+		})
+	}
+}
+
+func TestExpressionErrors(t *testing.T) {
+	var tests = []string{
+		`restarts >`,
+		`unknown > 5`,
+		`$9 > 5`,
+		`(restarts > 5`,
+		`name == "unterminated`,
+		`name matches "[a-"`,
+		`age < 5xyz`,
+		`restarts # 5`,
+		`unknown(age)`,
+		`seconds(age, 1)`,
+		`upper()`,
+		`restarts * `,
+	}
+
+	for _, source := range tests {
+		testname := fmt.Sprintf("expression-error-%s", source)
+
+		t.Run(testname, func(t *testing.T) {
+			data := newExprData()
+
+			_, err := CompileExpression(source, &data)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestFilterByExpressions(t *testing.T) {
+	data := newExprData()
+
+	conf := cfg.Config{Expressions: []string{`restarts > 5`, `age < 1h`}}
+	newdata, changed, err := FilterRows(conf, &data)
+
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	assert.EqualValues(t, [][]string{{"beta", "CrashLoop", "35", "45m", "0.1"}}, newdata.entries)
+
+	conf.InvertMatch = true
+	newdata, _, err = FilterRows(conf, &data)
+
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, len(newdata.entries))
+	// -v inverts the combination of field filters and expressions:
+	// not (restarts > 5 and status = Completed)
+	conf = cfg.Config{
+		Rawfilters:  []string{"status=Completed"},
+		Expressions: []string{`restarts > 5`},
+		InvertMatch: true,
+	}
+	assert.NoError(t, conf.PrepareFilters())
+
+	newdata, _, err = FilterRows(conf, &data)
+
+	assert.NoError(t, err)
+	assert.EqualValues(t, [][]string{
+		{"alpha", "Running", "0", "11d", "0.5"},
+		{"beta", "CrashLoop", "35", "45m", "0.1"},
+	}, newdata.entries)
+}
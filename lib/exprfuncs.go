@@ -0,0 +1,202 @@
+/*
+Copyright © 2025 Thomas von Dein
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package lib
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/tlinden/tablizer/cfg"
+)
+
+// a function usable in expressions, maxargs -1 means unlimited
+type exprFunc struct {
+	minargs int
+	maxargs int
+	call    func(args []exprValue) exprValue
+}
+
+// functions usable in expressions, names are case insensitive
+var exprFuncs = map[string]exprFunc{
+	// durations, sizes and timestamps
+	"seconds":  {1, 1, funcSeconds},
+	"duration": {1, 1, funcDuration},
+	"bytes":    {1, 1, funcBytes},
+	"size":     {1, 1, funcSize},
+	"now":      {0, 0, funcNow},
+	"since":    {1, 1, funcSince},
+
+	// numbers
+	"round": {1, 2, funcRound},
+	"abs":   {1, 1, funcAbs},
+
+	// strings
+	"len":     {1, 1, funcLen},
+	"upper":   {1, 1, stringFunc(strings.ToUpper)},
+	"lower":   {1, 1, stringFunc(strings.ToLower)},
+	"trim":    {1, 1, stringFunc(strings.TrimSpace)},
+	"substr":  {2, 3, funcSubstr},
+	"replace": {3, 3, funcReplace},
+	"concat":  {1, -1, funcConcat},
+}
+
+func numberValue(kind int, number float64) exprValue {
+	return exprValue{str: formatNumber(kind, number), kind: kind}
+}
+
+// convert a duration or timestamp into seconds, plain numbers are
+// considered to be seconds already
+func funcSeconds(args []exprValue) exprValue {
+	switch args[0].kind {
+	case cfg.TypeInt, cfg.TypeFloat, cfg.TypeTime:
+		if number, ok := args[0].number(); ok {
+			return numberValue(cfg.TypeInt, number)
+		}
+	default:
+		if !isNull(args[0].str) {
+			return numberValue(cfg.TypeInt, float64(duration2int(args[0].str)))
+		}
+	}
+
+	return exprValue{kind: cfg.TypeInt}
+}
+
+// convert seconds into a duration like 1h30m
+func funcDuration(args []exprValue) exprValue {
+	if number, ok := args[0].number(); ok {
+		return numberValue(cfg.TypeDuration, number)
+	}
+
+	return exprValue{kind: cfg.TypeDuration}
+}
+
+// convert a size like 1.5Gi into bytes
+func funcBytes(args []exprValue) exprValue {
+	if number, ok := size2float(strings.TrimSpace(args[0].str)); ok {
+		return numberValue(cfg.TypeInt, number)
+	}
+
+	if number, ok := args[0].number(); ok {
+		return numberValue(cfg.TypeInt, number)
+	}
+
+	return exprValue{kind: cfg.TypeInt}
+}
+
+// convert bytes into a size like 1.5Gi
+func funcSize(args []exprValue) exprValue {
+	if number, ok := args[0].number(); ok {
+		return numberValue(cfg.TypeSize, number)
+	}
+
+	return exprValue{kind: cfg.TypeSize}
+}
+
+func funcNow(_ []exprValue) exprValue {
+	return numberValue(cfg.TypeTime, float64(time.Now().Unix()))
+}
+
+// the time passed since a timestamp as duration
+func funcSince(args []exprValue) exprValue {
+	if ts, ok := toNumber(cfg.TypeTime, args[0].str); ok {
+		return numberValue(cfg.TypeDuration, float64(time.Now().Unix())-ts)
+	}
+
+	return exprValue{kind: cfg.TypeDuration}
+}
+
+// round to the given number of decimal places, 0 by default
+func funcRound(args []exprValue) exprValue {
+	number, ok := args[0].number()
+	if !ok {
+		return exprValue{kind: cfg.TypeFloat}
+	}
+
+	places := 0.0
+	if len(args) > 1 {
+		places, _ = args[1].number()
+	}
+
+	factor := math.Pow(10, math.Floor(places))
+	number = math.Round(number*factor) / factor
+
+	if places <= 0 {
+		return numberValue(cfg.TypeInt, number)
+	}
+
+	return exprValue{str: strconv.FormatFloat(number, 'f', -1, 64), kind: cfg.TypeFloat}
+}
+
+func funcAbs(args []exprValue) exprValue {
+	if number, ok := args[0].number(); ok {
+		return numberValue(args[0].kind, math.Abs(number))
+	}
+
+	return exprValue{kind: args[0].kind}
+}
+
+func funcLen(args []exprValue) exprValue {
+	return numberValue(cfg.TypeInt, float64(utf8.RuneCountInString(args[0].str)))
+}
+
+// wrap a simple string function
+func stringFunc(function func(string) string) func([]exprValue) exprValue {
+	return func(args []exprValue) exprValue {
+		return exprValue{str: function(args[0].str), kind: cfg.TypeString}
+	}
+}
+
+// substr(value, start[, length]), start is 0-based, negative values
+// count from the end
+func funcSubstr(args []exprValue) exprValue {
+	runes := []rune(args[0].str)
+
+	start, _ := args[1].number()
+	from := int(start)
+
+	if from < 0 {
+		from += len(runes)
+	}
+
+	from = max(0, min(from, len(runes)))
+	to := len(runes)
+
+	if len(args) > 2 {
+		length, _ := args[2].number()
+		to = max(from, min(from+int(length), len(runes)))
+	}
+
+	return exprValue{str: string(runes[from:to]), kind: cfg.TypeString}
+}
+
+func funcReplace(args []exprValue) exprValue {
+	return exprValue{str: strings.ReplaceAll(args[0].str, args[1].str, args[2].str), kind: cfg.TypeString}
+}
+
+func funcConcat(args []exprValue) exprValue {
+	var str strings.Builder
+
+	for _, arg := range args {
+		str.WriteString(arg.str)
+	}
+
+	return exprValue{str: str.String(), kind: cfg.TypeString}
+}
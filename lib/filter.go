@@ -19,7 +19,9 @@ package lib
 
 import (
 	"bufio"
+	"fmt"
 	"io"
+	"slices"
 	"strings"
 
 	"github.com/lithammer/fuzzysearch/fuzzy"
@@ -27,8 +29,23 @@ import (
 )
 
 /*
-* [!]Match a  line, use fuzzy  search for normal pattern  strings and
-* regexp otherwise.
+Decide if a line shall be kept, -v inverts the pattern match. If
+patterns are restricted to columns, the line is kept and FilterRows()
+does the work after parsing. The same applies if context rows have
+been requested, if -v has to be applied to patterns, field filters
+and expressions at once or if empty cells have to be filled first.
+*/
+func keepLine(conf cfg.Config, line string) bool {
+	if conf.RowPatterns() {
+		return true
+	}
+
+	return matchPattern(conf, line) != conf.InvertMatch
+}
+
+/*
+* [!]Match a line  against all patterns, use fuzzy  search if -z has
+* been given and regexp otherwise.
 
 		'foo bar'  foo, /bar/!  => false => line contains foo and not (not bar)
 	    'foo nix'  foo, /bar/!  => ture  => line contains foo and (not bar)
@@ -42,17 +59,19 @@ func matchPattern(conf cfg.Config, line string) bool {
 		return true
 	}
 
-	if conf.UseFuzzySearch {
-		// fuzzy search only considers the 1st pattern
-		return fuzzy.MatchFold(conf.Patterns[0].Pattern, line)
-	}
+	return matchPatterns(conf, func(_ int, pattern *cfg.Pattern) bool {
+		return matchValue(conf, pattern, line)
+	})
+}
 
+// apply all patterns using the given matcher, negated patterns toggle
+// the match. All patterns have to match, or any of them with --any.
+func matchPatterns(conf cfg.Config, matcher func(int, *cfg.Pattern) bool) bool {
 	var match int
 
-	//fmt.Printf("<%s>\n", line)
-	for _, re := range conf.Patterns {
-		patmatch := re.PatternRe.MatchString(line)
-		if re.Negate {
+	for idx, pattern := range conf.Patterns {
+		patmatch := matcher(idx, pattern)
+		if pattern.Negate {
 			// toggle the meaning of match
 			patmatch = !patmatch
 		}
@@ -60,51 +79,403 @@ func matchPattern(conf cfg.Config, line string) bool {
 		if patmatch {
 			match++
 		}
+	}
 
-		//fmt.Printf("patmatch: %t, match: %d, pattern: %s, negate: %t\n", patmatch, match, re.Pattern, re.Negate)
+	if conf.AnyPattern {
+		return match > 0
 	}
 
-	// fmt.Printf("result: %t\n", match == len(conf.Patterns))
-	//fmt.Println()
 	return match == len(conf.Patterns)
 }
 
+// decides if a parsed row matches
+type rowMatcher func(row []string) bool
+
 /*
- * Filter parsed data by fields. The  filter is positive, so if one or
- * more filters match on a row, it  will be kept, otherwise it will be
- * excluded.
+ * Filter parsed  rows by patterns,  field filters and  expressions. A
+ * row is  a hit if  it matches all of  them, -v inverts  the combined
+ * match. Patterns are only matched here  if they are restricted to
+ * columns, if context rows have been requested, if -v has to be
+ * applied to the combination or with --fill-down, otherwise the
+ * parsers match the raw input lines. Context rows before and after
+ * each hit are kept as well, like grep -B and -A.
  */
-func FilterByFields(conf cfg.Config, data *Tabdata) (*Tabdata, bool, error) {
-	if len(conf.Filters) == 0 {
-		// no filters, no checking
+func FilterRows(conf cfg.Config, data *Tabdata) (*Tabdata, bool, error) {
+	matchers := []rowMatcher{}
+
+	if len(conf.Patterns) > 0 && conf.RowPatterns() {
+		columns, err := patternColumns(conf, data)
+		if err != nil {
+			return nil, false, err
+		}
+
+		matchers = append(matchers, func(row []string) bool {
+			return matchRow(conf, columns, row)
+		})
+	}
+
+	if len(conf.Filters) > 0 {
+		matchers = append(matchers, fieldMatcher(conf, data))
+	}
+
+	if len(conf.Expressions) > 0 {
+		matcher, err := expressionMatcher(conf, data)
+		if err != nil {
+			return nil, false, err
+		}
+
+		matchers = append(matchers, matcher)
+	}
+
+	if len(matchers) == 0 {
 		return nil, false, nil
 	}
 
+	newdata := data.CloneEmpty()
+	lastkept := -1 // index of the last row added
+	after := 0     // number of context rows still to add after a hit
+
+	// remember the context rows, so that they can be marked later
+	keep := func(row []string, context bool) {
+		newdata.entries = append(newdata.entries, row)
+
+		if conf.UseContext() {
+			newdata.context = append(newdata.context, context)
+		}
+	}
+
+	for idx, row := range data.entries {
+		match := true
+
+		for _, matcher := range matchers {
+			if !matcher(row) {
+				match = false
+
+				break
+			}
+		}
+
+		if match != conf.InvertMatch {
+			// also apply -v, add preceding context rows first
+			for before := max(idx-conf.BeforeContext, lastkept+1); before < idx; before++ {
+				keep(data.entries[before], true)
+			}
+
+			keep(row, false)
+			lastkept = idx
+			after = conf.AfterContext
+
+			continue
+		}
+
+		if after > 0 {
+			keep(row, true)
+			lastkept = idx
+			after--
+		}
+	}
+
+	return &newdata, true, nil
+}
+
+// match a parsed row against all patterns
+func matchRow(conf cfg.Config, columns [][]int, row []string) bool {
+	line := strings.Join(row, " ")
+
+	return matchPatterns(conf, func(idx int, pattern *cfg.Pattern) bool {
+		if columns[idx] == nil {
+			return matchValue(conf, pattern, line)
+		}
+
+		for _, col := range columns[idx] {
+			if col <= len(row) && matchValue(conf, pattern, row[col-1]) {
+				return true
+			}
+		}
+
+		return false
+	})
+}
+
+// resolve the columns of every pattern, nil means the whole row
+func patternColumns(conf cfg.Config, data *Tabdata) ([][]int, error) {
+	columns := make([][]int, len(conf.Patterns))
+
+	for idx, pattern := range conf.Patterns {
+		if err := resolvePatternScope(pattern, data); err != nil {
+			return nil, err
+		}
+
+		if pattern.Columns == "" {
+			continue
+		}
+
+		usecolumns, err := PrepareColumnVars(pattern.Columns, data)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, col := range usecolumns {
+			if col < 1 || col > len(data.headers) {
+				return nil, fmt.Errorf("pattern column %d does not exist", col)
+			}
+		}
+
+		if len(usecolumns) == 0 {
+			return nil, fmt.Errorf("no column matches %s of pattern %s",
+				pattern.Columns, pattern.Pattern)
+		}
+
+		columns[idx] = usecolumns
+	}
+
+	return columns, nil
+}
+
+/*
+A pattern like name:/^api-/ is only restricted to columns if they
+exist, otherwise the whole string is used as pattern, so that eg
+https://x.org/ keeps working. The decision is made once, when the
+headers are known, the pattern is replaced by its scoped variant.
+*/
+func resolvePatternScope(pattern *cfg.Pattern, data *Tabdata) error {
+	if pattern.Scope == nil {
+		return nil
+	}
+
+	scope := pattern.Scope
+	pattern.Scope = nil
+
+	usecolumns, err := PrepareColumnVars(scope.Columns, data)
+	exists := err == nil && len(usecolumns) > 0 &&
+		!slices.ContainsFunc(usecolumns, func(col int) bool {
+			return col < 1 || col > len(data.headers)
+		})
+
+	switch {
+	case exists:
+		*pattern = *scope
+	case pattern.PatternRe == nil:
+		return fmt.Errorf("no column matches %s of pattern %s", scope.Columns, scope.Pattern)
+	}
+
+	return nil
+}
+
+// match a single value against a pattern
+func matchValue(conf cfg.Config, pattern *cfg.Pattern, value string) bool {
+	if conf.UseFuzzySearch {
+		return fuzzy.MatchFold(pattern.Pattern, value)
+	}
+
+	return pattern.PatternRe.MatchString(value)
+}
+
+/*
+ * Match  parsed rows  by fields.  A row  matches if  all filter  groups
+ * match, a group matches if any of its filters matches. Filters on
+ * fields which do not exist are ignored. Filters using one of < <= >
+ * >= compare values according to the column type, = and != match a
+ * regex or, on typed columns, a range like 1G..5G.
+ */
+func fieldMatcher(conf cfg.Config, data *Tabdata) rowMatcher {
+	// resolve the column of every filter in advance
+	groups := make([][]fieldFilter, 0, len(conf.Filters))
+
+	for _, group := range conf.Filters {
+		resolved := resolveFilterGroup(group, data)
+
+		if len(resolved) > 0 {
+			// do not filter by unspecified fields
+			groups = append(groups, resolved)
+		}
+	}
+
+	return func(row []string) bool {
+		for _, group := range groups {
+			if !matchFilterGroup(group, row) {
+				return false
+			}
+		}
+
+		return true
+	}
+}
+
+// a field filter with its column resolved
+type fieldFilter struct {
+	filter cfg.Filter
+	column int
+	kind   int
+}
+
+// resolve the columns of a filter group, filters on fields which do
+// not exist are left out
+func resolveFilterGroup(group cfg.FilterGroup, data *Tabdata) []fieldFilter {
+	resolved := []fieldFilter{}
+
+	for _, filter := range group {
+		for col, header := range data.headers {
+			if strings.ToLower(header) == filter.Field {
+				kind := filterColumnType(data, col)
+
+				// ordered comparisons on string columns use the type
+				// of the filter value, so that 9 < 10 even if the
+				// column contains other values as well
+				if kind == cfg.TypeString && filter.Op != "=" && filter.Op != "!=" {
+					kind = inferType([]string{filter.Value})
+				}
+
+				resolved = append(resolved, fieldFilter{
+					filter: filter,
+					column: col,
+					kind:   kind,
+				})
+
+				break
+			}
+		}
+	}
+
+	return resolved
+}
+
+// check if any filter of a group matches the row
+func matchFilterGroup(group []fieldFilter, row []string) bool {
+	for _, field := range group {
+		if field.column < len(row) && matchFilter(field.filter, field.kind, row[field.column]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// column type used by field filters, inferred on demand if the data
+// has not been typed yet
+func filterColumnType(data *Tabdata, col int) int {
+	if len(data.types) == len(data.headers) {
+		return data.columnType(col)
+	}
+
+	return inferType(columnValues(data, col))
+}
+
+// check if a single value matches a field filter
+func matchFilter(filter cfg.Filter, kind int, value string) bool {
+	var match bool
+
+	switch filter.Op {
+	case "=", "!=":
+		if low, high, isrange := filterRange(kind, filter.Value); isrange {
+			number, ok := toNumber(kind, value)
+			match = ok && number >= low && number <= high
+		} else {
+			match = filter.Regex.MatchString(value)
+		}
+
+		if filter.Negate {
+			match = !match
+		}
+	default:
+		result, ok := compareTyped(kind, value, filter.Value)
+		if !ok {
+			return false
+		}
+
+		switch filter.Op {
+		case "<":
+			match = result < 0
+		case "<=":
+			match = result <= 0
+		case ">":
+			match = result > 0
+		case ">=":
+			match = result >= 0
+		}
+	}
+
+	return match
+}
+
+// a filter value like 1G..5G is a range if the column is typed and
+// both ends can be converted to the column type
+func filterRange(kind int, value string) (float64, float64, bool) {
+	if kind == cfg.TypeString || kind == cfg.TypeIP {
+		return 0, 0, false
+	}
+
+	low, high, found := strings.Cut(value, "..")
+	if !found {
+		return 0, 0, false
+	}
+
+	lownum, lowok := toNumber(kind, low)
+	highnum, highok := toNumber(kind, high)
+
+	if !lowok || !highok {
+		return 0, 0, false
+	}
+
+	return lownum, highnum, true
+}
+
+/*
+ * Filter parsed data by key sets given with --in and --not-in. A row is
+ * kept if  the value of the  column appears in the  key set, or doesn't
+ * with --not-in. The key set is either a plain list or a column of
+ * another table.
+ */
+func FilterByKeys(conf cfg.Config, data *Tabdata) (*Tabdata, bool, error) {
+	if len(conf.KeyFilters) == 0 {
+		return nil, false, nil
+	}
+
+	columns := make([][]int, len(conf.KeyFilters))
+	keysets := make([]map[string]bool, len(conf.KeyFilters))
+
+	for idx, filter := range conf.KeyFilters {
+		usecolumns, err := PrepareColumnVars(filter.Column, data)
+		if err != nil {
+			return nil, false, err
+		}
+
+		if len(usecolumns) == 0 {
+			return nil, false, fmt.Errorf("no column matches %s", filter.Column)
+		}
+
+		columns[idx] = usecolumns
+
+		keysets[idx], err = loadKeys(conf, filter)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+
 	newdata := data.CloneEmpty()
 
 	for _, row := range data.entries {
 		keep := true
 
-		for idx, header := range data.headers {
-			lcheader := strings.ToLower(header)
-			if !Exists(conf.Filters, lcheader) {
-				// do not filter by unspecified field
-				continue
-			}
+		for idx, filter := range conf.KeyFilters {
+			found := false
+
+			for _, col := range columns[idx] {
+				if col <= len(row) && keysets[idx][strings.TrimSpace(row[col-1])] {
+					found = true
 
-			match := conf.Filters[lcheader].Regex.MatchString(row[idx])
-			if conf.Filters[lcheader].Negate {
-				match = !match
+					break
+				}
 			}
 
-			if !match {
+			if found == filter.Negate {
 				keep = false
+
 				break
 			}
 		}
 
-		if keep == !conf.InvertMatch {
-			// also apply -v
+		if keep {
 			newdata.entries = append(newdata.entries, row)
 		}
 	}
@@ -112,8 +483,80 @@ func FilterByFields(conf cfg.Config, data *Tabdata) (*Tabdata, bool, error) {
 	return &newdata, true, nil
 }
 
+// load the key set of a key filter
+func loadKeys(conf cfg.Config, filter cfg.KeyFilter) (map[string]bool, error) {
+	keys := map[string]bool{}
+
+	if filter.KeyColumn == "" {
+		values, err := loadList(filter.File)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, value := range values {
+			keys[value] = true
+		}
+
+		return keys, nil
+	}
+
+	table, err := loadTable(conf, filter.File, cfg.SeparatorTemplates[":default:"])
+	if err != nil {
+		return nil, err
+	}
+
+	keycolumns, err := PrepareColumnVars(filter.KeyColumn, &table)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(keycolumns) == 0 {
+		return nil, fmt.Errorf("no column matches %s in %s", filter.KeyColumn, filter.File)
+	}
+
+	for _, row := range table.entries {
+		for _, col := range keycolumns {
+			if col <= len(row) {
+				keys[strings.TrimSpace(row[col-1])] = true
+			}
+		}
+	}
+
+	return keys, nil
+}
+
+/*
+ * Match parsed rows by expressions given  with -E. A row matches if
+ * all expressions evaluate to true.
+ */
+func expressionMatcher(conf cfg.Config, data *Tabdata) (rowMatcher, error) {
+	expressions := make([]*Expression, len(conf.Expressions))
+
+	for idx, source := range conf.Expressions {
+		expr, err := CompileExpression(source, data)
+		if err != nil {
+			return nil, err
+		}
+
+		expressions[idx] = expr
+	}
+
+	return func(row []string) bool {
+		for _, expr := range expressions {
+			if !expr.Match(row) {
+				return false
+			}
+		}
+
+		return true
+	}, nil
+}
+
 /*
- * Transpose fields using search/replace regexp.
+ * Transpose fields using search/replace regexps or functions. Multiple
+ * transposers for the same column are applied in the order given.
+ * Conditional transposers are only applied if the original row
+ * matches their condition.
  */
 func TransposeFields(conf cfg.Config, data *Tabdata) (*Tabdata, bool, error) {
 	if len(conf.UseTransposers) == 0 {
@@ -121,32 +564,63 @@ func TransposeFields(conf cfg.Config, data *Tabdata) (*Tabdata, bool, error) {
 		return nil, false, nil
 	}
 
+	conditions := make([][]fieldFilter, len(conf.UseTransposers))
+
+	for idx, transposer := range conf.UseTransposers {
+		conditions[idx] = resolveFilterGroup(transposer.Condition, data)
+
+		for _, filter := range transposer.Condition {
+			if !slices.ContainsFunc(conditions[idx], func(field fieldFilter) bool {
+				return field.filter.Field == filter.Field
+			}) {
+				return nil, false, fmt.Errorf("transposer condition column %s does not exist", filter.Field)
+			}
+		}
+	}
+
 	newdata := data.CloneEmpty()
-	transposed := false
+	newdata.context = data.context
 
 	for _, row := range data.entries {
-		transposedrow := false
-
-		for idx := range data.headers {
-			transposeidx, hasone := findindex(conf.UseTransposeColumns, idx+1)
-			if hasone {
-				row[idx] =
-					conf.UseTransposers[transposeidx].Search.ReplaceAllString(
-						row[idx],
-						conf.UseTransposers[transposeidx].Replace,
-					)
-				transposedrow = true
+		transposedrow := slices.Clone(row)
+
+		for idx, transposer := range conf.UseTransposers {
+			col := conf.UseTransposeColumns[idx] - 1
+			if col < 0 || col >= len(transposedrow) {
+				continue
+			}
+
+			if len(conditions[idx]) > 0 && !matchFilterGroup(conditions[idx], row) {
+				continue
 			}
+
+			transposedrow[col] = transpose(transposer, transposedrow[col])
 		}
 
-		if transposedrow {
-			// also apply -v
-			newdata.entries = append(newdata.entries, row)
-			transposed = true
+		newdata.entries = append(newdata.entries, transposedrow)
+	}
+
+	return &newdata, true, nil
+}
+
+// apply a single transposer to a value
+func transpose(transposer cfg.Transposer, value string) string {
+	switch transposer.Function {
+	case "upper":
+		return strings.ToUpper(value)
+	case "lower":
+		return strings.ToLower(value)
+	case "trim":
+		return strings.TrimSpace(value)
+	case "truncate":
+		if runes := []rune(value); len(runes) > transposer.Length {
+			return string(runes[:transposer.Length])
 		}
+
+		return value
 	}
 
-	return &newdata, transposed, nil
+	return transposer.Search.ReplaceAllString(value, transposer.Replace)
 }
 
 /* generic map.Exists(key) */
@@ -159,7 +633,9 @@ func Exists[K comparable, V any](m map[K]V, v K) bool {
 }
 
 /*
- * Filters the whole input lines, returns filtered lines
+ * Filters the whole input lines, returns filtered lines. Lines which
+ * have been filtered out are  replaced by empty lines, which the CSV
+ * reader ignores, so that line numbers still refer to the input.
  */
 func FilterByPattern(conf cfg.Config, input io.Reader) (io.Reader, error) {
 	if len(conf.Patterns) == 0 {
@@ -172,14 +648,15 @@ func FilterByPattern(conf cfg.Config, input io.Reader) (io.Reader, error) {
 
 	for scanner.Scan() {
 		line := strings.TrimSpace(scanner.Text())
-		if hadFirst {
-			// don't match 1st line, it's the header
-			if matchPattern(conf, line) == conf.InvertMatch {
+
+		// don't match 1st line if it's the header
+		if hadFirst || conf.AutoHeaders || len(conf.CustomHeaders) > 0 {
+			if !keepLine(conf, line) {
 				// by default  -v is false, so if a  line does NOT
 				// match the pattern, we will ignore it. However,
 				// if the user specified -v, the matching is inverted,
 				// so we ignore all lines, which DO match.
-				continue
+				line = ""
 			}
 		}
 
@@ -188,5 +665,9 @@ func FilterByPattern(conf cfg.Config, input io.Reader) (io.Reader, error) {
 		hadFirst = true
 	}
 
+	if scanner.Err() != nil {
+		return nil, fmt.Errorf("failed to read from io.Reader: %w", scanner.Err())
+	}
+
 	return strings.NewReader(strings.Join(lines, "\n")), nil
 }
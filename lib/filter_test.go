@@ -19,8 +19,13 @@ package lib
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
 	"testing"
 
+	"github.com/gookit/color"
 	"github.com/stretchr/testify/assert"
 	"github.com/tlinden/tablizer/cfg"
 )
@@ -43,6 +48,18 @@ func TestMatchPattern(t *testing.T) {
 			line:     "haus-party-termin",
 			fuzzy:    true,
 		},
+		{
+			name:     "fuzzy-multiple-terms",
+			patterns: []*cfg.Pattern{{Pattern: "hpt"}, {Pattern: "trm"}},
+			line:     "haus-party-termin",
+			fuzzy:    true,
+		},
+		{
+			name:     "fuzzy-negated-term",
+			patterns: []*cfg.Pattern{{Pattern: "hpt"}, {Pattern: "/xyz/!"}},
+			line:     "haus-party-termin",
+			fuzzy:    true,
+		},
 	}
 
 	for _, inputdata := range input {
@@ -164,9 +181,294 @@ func TestFilterByFields(t *testing.T) {
 
 			assert.NoError(t, err)
 
-			data, _, _ := FilterByFields(conf, &data)
+			data, _, _ := FilterRows(conf, &data)
 
 			assert.EqualValues(t, inputdata.expect, *data)
 		})
 	}
 }
+
+func TestFilterByTypedFields(t *testing.T) {
+	data := Tabdata{
+		headers: []string{"NAME", "RESTARTS", "CPU", "AGE", "SIZE", "VERSION"},
+		entries: [][]string{
+			{"alpha", "3", "0.25", "45m", "512Mi", "9"},
+			{"beta", "10", "0.5", "3d", "2G", "10"},
+			{"gamma", "12", "1.5", "1d", "10G", "unknown"},
+		},
+	}
+
+	var input = []struct {
+		filter []string
+		expect []string
+	}{
+		{[]string{"restarts>=10"}, []string{"beta", "gamma"}},
+		{[]string{"restarts>10"}, []string{"gamma"}},
+		{[]string{"cpu<0.5"}, []string{"alpha"}},
+		{[]string{"cpu<=0.5"}, []string{"alpha", "beta"}},
+		{[]string{"age>2d"}, []string{"beta"}},
+		{[]string{"age>1h", "age<2d"}, []string{"gamma"}},
+		{[]string{"size=1G..5G"}, []string{"beta"}},
+		{[]string{"size!=1G..5G"}, []string{"alpha", "gamma"}},
+		{[]string{"name=a..a"}, []string{"gamma"}},
+		{[]string{"name>beta"}, []string{"gamma"}},
+		{[]string{"version>=10"}, []string{"beta"}},
+		{[]string{"version<10"}, []string{"alpha"}},
+		{[]string{"restarts<5|age>2d"}, []string{"alpha", "beta"}},
+		{[]string{"restarts<5|age>2d", "cpu>0.3"}, []string{"beta"}},
+		{[]string{"name=alpha|nonexistent=x"}, []string{"alpha"}},
+	}
+
+	for _, inputdata := range input {
+		testname := fmt.Sprintf("filter-by-typed-fields-%s", strings.Join(inputdata.filter, ","))
+
+		t.Run(testname, func(t *testing.T) {
+			conf := cfg.Config{Rawfilters: inputdata.filter}
+			assert.NoError(t, conf.PrepareFilters())
+
+			data.types = nil
+			assert.NoError(t, InferTypes(conf, &data))
+
+			newdata, _, err := FilterRows(conf, &data)
+			assert.NoError(t, err)
+
+			got := []string{}
+			for _, row := range newdata.entries {
+				got = append(got, row[0])
+			}
+
+			assert.EqualValues(t, inputdata.expect, got)
+		})
+	}
+}
+
+func TestFilterByPatterns(t *testing.T) {
+	data := Tabdata{
+		headers: []string{"NAME", "STATUS", "RESTARTS"},
+		entries: [][]string{
+			{"api-server", "Running", "1"},
+			{"web-api", "Error", "10"},
+			{"db", "Running", "0"},
+		},
+	}
+
+	var input = []struct {
+		patterns []string
+		columns  string
+		any      bool
+		invert   bool
+		expect   []string
+		wanterr  bool
+	}{
+		{patterns: []string{"name:/^api-/"}, expect: []string{"api-server"}},
+		{patterns: []string{"name:/API/i"}, expect: []string{"api-server", "web-api"}},
+		{patterns: []string{"/1/"}, columns: "restarts", expect: []string{"api-server", "web-api"}},
+		{patterns: []string{"/1/"}, columns: "name", expect: []string{}},
+		{patterns: []string{"name:/api/", "Running"}, expect: []string{"api-server"}},
+		{patterns: []string{"name:/^db/", "status:/Error/"}, any: true, expect: []string{"web-api", "db"}},
+		{patterns: []string{"name:/api/!"}, expect: []string{"db"}},
+		{patterns: []string{"name:/api/"}, invert: true, expect: []string{"db"}},
+		{patterns: []string{"nothere:/api/"}, expect: []string{}},
+		{patterns: []string{"/api/"}, columns: "nothere", wanterr: true},
+	}
+
+	for _, inputdata := range input {
+		testname := fmt.Sprintf("filter-by-patterns-%s-%s", strings.Join(inputdata.patterns, ","), inputdata.columns)
+
+		t.Run(testname, func(t *testing.T) {
+			conf := cfg.Config{
+				PatternColumns: inputdata.columns,
+				AnyPattern:     inputdata.any,
+				InvertMatch:    inputdata.invert,
+			}
+
+			patterns := []*cfg.Pattern{}
+			for _, pattern := range inputdata.patterns {
+				patterns = append(patterns, &cfg.Pattern{Pattern: pattern})
+			}
+
+			assert.NoError(t, conf.PreparePattern(patterns))
+
+			newdata, changed, err := FilterRows(conf, &data)
+			if inputdata.wanterr {
+				assert.Error(t, err)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.True(t, changed)
+
+			got := []string{}
+			for _, row := range newdata.entries {
+				got = append(got, row[0])
+			}
+
+			assert.EqualValues(t, inputdata.expect, got)
+		})
+	}
+}
+
+func TestHighlightColumns(t *testing.T) {
+	data := Tabdata{
+		headers: []string{"NAME", "STATUS"},
+		entries: [][]string{
+			{"api", "api"},
+		},
+	}
+
+	conf := cfg.Config{ColorStyle: color.New(color.Red)}
+	patterns := []*cfg.Pattern{{Pattern: "status:/api/"}}
+	assert.NoError(t, conf.PreparePattern(patterns))
+
+	highlightColumns(conf, &data)
+
+	assert.EqualValues(t, "api", data.entries[0][0])
+	assert.NotEqualValues(t, "api", data.entries[0][1])
+	assert.Contains(t, data.entries[0][1], "api")
+}
+
+func TestFilterByPatternsContext(t *testing.T) {
+	data := Tabdata{
+		headers: []string{"NAME"},
+		entries: [][]string{
+			{"one"}, {"two"}, {"three"}, {"hit1"}, {"four"},
+			{"five"}, {"six"}, {"hit2"}, {"hit3"}, {"seven"},
+		},
+	}
+
+	var input = []struct {
+		before, after int
+		invert        bool
+		expect        []string
+	}{
+		{1, 0, false, []string{"three", "hit1", "six", "hit2", "hit3"}},
+		{0, 1, false, []string{"hit1", "four", "hit2", "hit3", "seven"}},
+		{2, 2, false, []string{"two", "three", "hit1", "four", "five", "six", "hit2", "hit3", "seven"}},
+		{0, 1, true, []string{"one", "two", "three", "hit1", "four", "five", "six", "hit2", "seven"}},
+	}
+
+	for _, inputdata := range input {
+		testname := fmt.Sprintf("filter-by-patterns-context-B%d-A%d-invert-%t",
+			inputdata.before, inputdata.after, inputdata.invert)
+
+		t.Run(testname, func(t *testing.T) {
+			conf := cfg.Config{
+				BeforeContext: inputdata.before,
+				AfterContext:  inputdata.after,
+				InvertMatch:   inputdata.invert,
+			}
+
+			assert.NoError(t, conf.PreparePattern([]*cfg.Pattern{{Pattern: "hit"}}))
+
+			newdata, changed, err := FilterRows(conf, &data)
+			assert.NoError(t, err)
+			assert.True(t, changed)
+
+			got := []string{}
+			for _, row := range newdata.entries {
+				got = append(got, row[0])
+			}
+
+			assert.EqualValues(t, inputdata.expect, got)
+		})
+	}
+}
+
+func TestMarkContext(t *testing.T) {
+	data := Tabdata{
+		headers: []string{"NAME"},
+		entries: [][]string{{"other"}, {"zcontext"}, {"hit"}},
+	}
+
+	conf := cfg.Config{
+		BeforeContext:   1,
+		ContextStyle:    color.New(color.OpFuzzy),
+		SortMode:        "string",
+		UseSortByColumn: []int{1},
+	}
+	assert.NoError(t, conf.PreparePattern([]*cfg.Pattern{{Pattern: "hit"}}))
+
+	newdata, changed, err := FilterRows(conf, &data)
+	assert.NoError(t, err)
+	assert.True(t, changed)
+
+	// the context row has to be marked after being moved by sorting
+	sortTable(conf, newdata)
+	markContext(conf, newdata)
+
+	assert.EqualValues(t, "hit", newdata.entries[0][0])
+	assert.EqualValues(t, conf.ContextStyle.Sprint("zcontext"), newdata.entries[1][0])
+}
+
+func TestHighlightPlain(t *testing.T) {
+	style := color.New(color.Red)
+	re := regexp.MustCompile("(m|0)")
+
+	highlight := func(text string) string {
+		return re.ReplaceAllStringFunc(text, func(in string) string {
+			return style.Sprint(in)
+		})
+	}
+
+	context := color.New(color.OpFuzzy).Sprint("m0")
+
+	assert.EqualValues(t, style.Sprint("m")+"x"+context,
+		highlightPlain("mx"+context, highlight))
+}
+
+func TestFilterByKeys(t *testing.T) {
+	dir := t.TempDir()
+
+	list := filepath.Join(dir, "nodes.txt")
+	assert.NoError(t, os.WriteFile(list, []byte("# drain these\nnode1\n\nnode3\n"), 0644))
+
+	table := filepath.Join(dir, "nodes.csv")
+	assert.NoError(t, os.WriteFile(table, []byte("NAME,STATUS\nnode2,Ready\nnode3,NotReady\n"), 0644))
+
+	// not parsed using the separator of the input
+	plain := filepath.Join(dir, "nodes.tbl")
+	assert.NoError(t, os.WriteFile(plain, []byte("NAME    STATUS\nnode1   Ready\nnode2   NotReady\n"), 0644))
+
+	var input = []struct {
+		name   string
+		filter cfg.KeyFilter
+		expect []string
+	}{
+		{"list", cfg.KeyFilter{Column: "node", File: list}, []string{"pod-a", "pod-c"}},
+		{"list-negated", cfg.KeyFilter{Column: "node", File: list, Negate: true}, []string{"pod-b"}},
+		{"table", cfg.KeyFilter{Column: "node", File: table, KeyColumn: "name"}, []string{"pod-b", "pod-c"}},
+		{"plain-table", cfg.KeyFilter{Column: "node", File: plain, KeyColumn: "name"}, []string{"pod-a", "pod-b"}},
+	}
+
+	for _, inputdata := range input {
+		testname := fmt.Sprintf("filter-by-keys-%s", inputdata.name)
+
+		t.Run(testname, func(t *testing.T) {
+			data := Tabdata{
+				headers: []string{"NAME", "NODE"},
+				entries: [][]string{
+					{"pod-a", "node1"},
+					{"pod-b", "node2"},
+					{"pod-c", "node3"},
+				},
+			}
+
+			conf := cfg.Config{
+				Separator:  ";",
+				KeyFilters: []cfg.KeyFilter{inputdata.filter},
+			}
+
+			newdata, changed, err := FilterByKeys(conf, &data)
+			assert.NoError(t, err)
+			assert.True(t, changed)
+
+			got := []string{}
+			for _, row := range newdata.entries {
+				got = append(got, row[0])
+			}
+
+			assert.EqualValues(t, inputdata.expect, got)
+		})
+	}
+}
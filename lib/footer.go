@@ -0,0 +1,117 @@
+/*
+Copyright © 2025 Thomas von Dein
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package lib
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/tlinden/tablizer/cfg"
+)
+
+// a calculated aggregation of the footer row
+type footerValue struct {
+	header string // eg sum_restarts
+	name   string // aggregate function
+	column int    // 0-based column of the displayed table, -1 if not displayed
+	kind   int
+	value  string
+}
+
+// resolve the columns of the --footer aggregations, this is an output
+// option, so it is being applied to the final table
+func PrepareFooter(conf cfg.Config, data *Tabdata) error {
+	if len(conf.Footer) == 0 {
+		return nil
+	}
+
+	aggregators, err := prepareAggregators(conf.Footer, data)
+	if err != nil {
+		return fmt.Errorf("failed to prepare footer: %w", err)
+	}
+
+	data.footer = aggregators
+
+	return nil
+}
+
+/*
+Calculate  the footer  aggregations over  all rows  to be  printed,
+before they  are being limited by  --head and the like,  and determine
+the column of each value in the displayed table. A count without
+column is placed into the first column. Rotated tables have no footer
+row, but YAML output still contains the values.
+*/
+func calculateFooter(conf cfg.Config, data *Tabdata) error {
+	data.footerValues = nil
+
+	for _, aggr := range data.footer {
+		value, err := aggr.aggregate(data.entries)
+		if err != nil {
+			return fmt.Errorf("failed to calculate footer: %w", err)
+		}
+
+		column := max(aggr.column, 0)
+
+		if len(conf.UseColumns) > 0 && aggr.column >= 0 {
+			column = slices.Index(conf.UseColumns, aggr.column+1)
+		}
+
+		if conf.Rotate {
+			column = -1
+		}
+
+		data.footerValues = append(data.footerValues, footerValue{
+			header: aggr.agg.Header,
+			name:   aggr.agg.Func,
+			column: column,
+			kind:   aggr.resultType(),
+			value:  value,
+		})
+	}
+
+	return nil
+}
+
+// the footer row as displayed by the table printers, nil if empty
+func footerRow(data *Tabdata) []string {
+	cells := make([][]string, len(data.headers))
+	empty := true
+
+	for _, footer := range data.footerValues {
+		if footer.column < 0 || footer.column >= len(cells) {
+			continue
+		}
+
+		cells[footer.column] = append(cells[footer.column],
+			fmt.Sprintf("%s: %s", footer.name, footer.value))
+		empty = false
+	}
+
+	if empty {
+		return nil
+	}
+
+	row := make([]string, len(cells))
+	for idx, values := range cells {
+		row[idx] = strings.Join(values, ", ")
+	}
+
+	return row
+}
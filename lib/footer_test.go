@@ -0,0 +1,178 @@
+/*
+Copyright © 2025 Thomas von Dein
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package lib
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tlinden/tablizer/cfg"
+)
+
+func TestFooterRow(t *testing.T) {
+	var tests = []struct {
+		footer  string
+		columns []int
+		rotate  bool
+		expect  []string
+	}{
+		{
+			"sum(restarts),count",
+			nil, false,
+			[]string{"count: 4", "", "sum: 14", "", "", ""},
+		},
+		{
+			"max(age),min(age),avg(cpu)",
+			nil, false,
+			[]string{"", "", "", "avg: 0.6875", "max: 2d, min: 30m", ""},
+		},
+		{
+			"sum(mem),count(name)",
+			[]int{6, 1}, false,
+			[]string{"sum: 3Gi", "count: 4"},
+		},
+		{
+			"sum(restarts)",
+			[]int{1, 2}, false,
+			nil,
+		},
+		{
+			"sum(restarts)",
+			nil, true,
+			nil,
+		},
+	}
+
+	for _, testdata := range tests {
+		testname := fmt.Sprintf("footer-row-%s-%v-%t",
+			testdata.footer, testdata.columns, testdata.rotate)
+
+		t.Run(testname, func(t *testing.T) {
+			data := newAggData()
+			conf := cfg.Config{RawFooter: testdata.footer, Rotate: testdata.rotate}
+
+			assert.NoError(t, conf.PrepareFooter())
+			assert.NoError(t, PrepareFooter(conf, &data))
+
+			if len(testdata.columns) > 0 {
+				conf.Columns = "yes"
+				conf.UseColumns = testdata.columns
+			}
+
+			calculateFooter(conf, &data)
+			reduceColumns(conf, &data)
+			numberizeAndReduceHeaders(conf, &data)
+
+			assert.EqualValues(t, testdata.expect, footerRow(&data))
+		})
+	}
+}
+
+func TestFooterErrors(t *testing.T) {
+	var tests = []string{"sum(name)", "avg(nothere)", "max(.)"}
+
+	for _, footer := range tests {
+		testname := fmt.Sprintf("footer-error-%s", footer)
+
+		t.Run(testname, func(t *testing.T) {
+			data := newAggData()
+			conf := cfg.Config{RawFooter: footer}
+
+			assert.NoError(t, conf.PrepareFooter())
+			assert.Error(t, PrepareFooter(conf, &data))
+		})
+	}
+}
+
+func TestPrintFooter(t *testing.T) {
+	var tests = []struct {
+		mode   int
+		expect string
+	}{
+		{cfg.CSV, "NAME,RESTARTS\na,1\nb,10\nc,3\nd,\ncount: 4,sum: 14"},
+		{cfg.Shell, `NAME="a" RESTARTS="1"
+NAME="b" RESTARTS="10"
+NAME="c" RESTARTS="3"
+NAME="d" RESTARTS=""
+sum_restarts="14" count="4"`},
+		{cfg.Markdown, `| **count: 4** | **sum: 14** |`},
+		{cfg.Yaml, `footer:
+    count: 4
+    sum_restarts: 14`},
+		{cfg.Extended, `sum_restarts: 14
+       count: 4`},
+	}
+
+	for _, testdata := range tests {
+		testname := fmt.Sprintf("print-footer-%d", testdata.mode)
+
+		t.Run(testname, func(t *testing.T) {
+			var writer bytes.Buffer
+
+			data := newAggData()
+			conf := cfg.Config{
+				RawFooter:  "sum(restarts),count",
+				OutputMode: testdata.mode,
+				Columns:    "name,restarts",
+				UseColumns: []int{1, 3},
+				NoColor:    true,
+			}
+
+			assert.NoError(t, conf.PrepareFooter())
+			assert.NoError(t, PrepareFooter(conf, &data))
+
+			assert.NoError(t, printData(&writer, conf, &data))
+
+			assert.Contains(t, strings.TrimSpace(writer.String()), testdata.expect)
+		})
+	}
+}
+
+func TestPrintFooterLimited(t *testing.T) {
+	var writer bytes.Buffer
+
+	data := newAggData()
+	conf := cfg.Config{RawFooter: "sum(restarts)", OutputMode: cfg.Json, Head: 1}
+
+	assert.NoError(t, conf.PrepareFooter())
+	assert.NoError(t, PrepareFooter(conf, &data))
+	assert.NoError(t, printData(&writer, conf, &data))
+
+	// the footer is calculated over all rows
+	assert.EqualValues(t, "14", data.footerValues[0].value)
+	assert.Contains(t, writer.String(), `"footer": {
+    "sum_restarts": 14
+  }`)
+}
+
+func TestPrintJsonWithoutFooter(t *testing.T) {
+	var writer bytes.Buffer
+
+	data := newAggData()
+	conf := cfg.Config{OutputMode: cfg.Json}
+
+	assert.NoError(t, conf.PrepareFooter())
+	assert.NoError(t, PrepareFooter(conf, &data))
+	assert.NoError(t, printData(&writer, conf, &data))
+
+	assert.True(t, strings.HasPrefix(writer.String(), "["))
+	assert.NotContains(t, writer.String(), "footer")
+}
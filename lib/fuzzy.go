@@ -0,0 +1,183 @@
+/*
+Copyright © 2025 Thomas von Dein
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package lib
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/gookit/color"
+	"github.com/lithammer/fuzzysearch/fuzzy"
+	"github.com/tlinden/tablizer/cfg"
+)
+
+/*
+Sort rows by fuzzy  rank, best matches first. The rank  of a row is the
+sum of the characters skipped by the tightest match of all fuzzy terms
+in the row or in  the best matching column of restricted terms, so the
+length of a row doesn't matter. Explicit  sorting using -k is done
+afterwards and takes precedence.
+*/
+func rankFuzzy(conf cfg.Config, data *Tabdata) {
+	if !conf.UseFuzzySearch || !conf.FuzzyRank || len(conf.Patterns) == 0 {
+		return
+	}
+
+	columns, err := patternColumns(conf, data)
+	if err != nil {
+		// already checked by FilterRows()
+		return
+	}
+
+	ranks := make([]int, len(data.entries))
+	order := make([]int, len(data.entries))
+
+	for idx, row := range data.entries {
+		ranks[idx] = fuzzyRank(conf, columns, row)
+		order[idx] = idx
+	}
+
+	sort.SliceStable(order, func(i, j int) bool {
+		return ranks[order[i]] < ranks[order[j]]
+	})
+
+	data.reorderRows(order)
+}
+
+// calculate the fuzzy rank of a row, lower is better
+func fuzzyRank(conf cfg.Config, columns [][]int, row []string) int {
+	rank := 0
+
+	for idx, pattern := range conf.Patterns {
+		if pattern.Negate {
+			continue
+		}
+
+		best := -1
+
+		values := []string{strings.Join(row, " ")}
+		if columns[idx] != nil {
+			values = []string{}
+
+			for _, col := range columns[idx] {
+				if col <= len(row) {
+					values = append(values, row[col-1])
+				}
+			}
+		}
+
+		for _, value := range values {
+			distance := fuzzySkipped(pattern.Pattern, value)
+			if distance >= 0 && (best < 0 || distance < best) {
+				best = distance
+			}
+		}
+
+		if best < 0 {
+			// may happen with --any, rank non matching terms last
+			best = len(strings.Join(row, " "))
+		}
+
+		rank += best
+	}
+
+	return rank
+}
+
+// return the number of characters  skipped by the tightest match of
+// term in value, -1 if it doesn't match at all
+func fuzzySkipped(term, value string) int {
+	termrunes := []rune(strings.ToLower(term))
+	runes := []rune(strings.ToLower(value))
+	best := -1
+
+	if len(termrunes) == 0 {
+		return 0
+	}
+
+	for start, char := range runes {
+		if char != termrunes[0] {
+			continue
+		}
+
+		pos := 1
+		end := start
+
+		for idx := start + 1; idx < len(runes) && pos < len(termrunes); idx++ {
+			if runes[idx] == termrunes[pos] {
+				pos++
+				end = idx
+			}
+		}
+
+		if pos < len(termrunes) {
+			// no further match possible after this start
+			break
+		}
+
+		if skipped := end - start + 1 - len(termrunes); best < 0 || skipped < best {
+			best = skipped
+		}
+	}
+
+	return best
+}
+
+/*
+Highlight the characters of text matched by the fuzzy terms. Terms
+which don't match don't highlight anything.
+*/
+func fuzzyHighlight(terms []string, text string, style color.Style) string {
+	runes := []rune(text)
+	marks := make([]bool, len(runes))
+	marked := false
+
+	for _, term := range terms {
+		if term == "" || !fuzzy.MatchFold(term, text) {
+			continue
+		}
+
+		termrunes := []rune(term)
+		pos := 0
+
+		for idx, char := range runes {
+			if pos < len(termrunes) && unicode.ToLower(char) == unicode.ToLower(termrunes[pos]) {
+				marks[idx] = true
+				marked = true
+				pos++
+			}
+		}
+	}
+
+	if !marked {
+		return text
+	}
+
+	var out strings.Builder
+
+	for idx, char := range runes {
+		if marks[idx] {
+			out.WriteString(style.Sprint(string(char)))
+		} else {
+			out.WriteRune(char)
+		}
+	}
+
+	return out.String()
+}
@@ -0,0 +1,149 @@
+/*
+Copyright © 2025 Thomas von Dein
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package lib
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/gookit/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/tlinden/tablizer/cfg"
+)
+
+func TestMatchFuzzyTerms(t *testing.T) {
+	var input = []struct {
+		terms  []string
+		any    bool
+		line   string
+		expect bool
+	}{
+		{[]string{"hpt", "trm"}, false, "haus-party-termin", true},
+		{[]string{"hpt", "xyz"}, false, "haus-party-termin", false},
+		{[]string{"hpt", "xyz"}, true, "haus-party-termin", true},
+		{[]string{"/xyz/!"}, false, "haus-party-termin", true},
+	}
+
+	for _, inputdata := range input {
+		testname := fmt.Sprintf("match-fuzzy-%s-any-%t", strings.Join(inputdata.terms, ","), inputdata.any)
+
+		t.Run(testname, func(t *testing.T) {
+			conf := cfg.Config{UseFuzzySearch: true, AnyPattern: inputdata.any}
+
+			patterns := []*cfg.Pattern{}
+			for _, term := range inputdata.terms {
+				patterns = append(patterns, &cfg.Pattern{Pattern: term})
+			}
+
+			assert.NoError(t, conf.PreparePattern(patterns))
+			assert.EqualValues(t, inputdata.expect, matchPattern(conf, inputdata.line))
+		})
+	}
+}
+
+func TestRankFuzzy(t *testing.T) {
+	data := Tabdata{
+		headers: []string{"NAME", "STATUS"},
+		entries: [][]string{
+			{"kube-prometheus-blackbox-exporter", "Running"},
+			{"grafana", "Running"},
+			{"kube-state", "Running"},
+			{"kube-setup", "Running"},
+		},
+	}
+
+	conf := cfg.Config{UseFuzzySearch: true, FuzzyRank: true}
+	assert.NoError(t, conf.PreparePattern([]*cfg.Pattern{{Pattern: "name:/kst/"}}))
+
+	newdata, _, err := FilterRows(conf, &data)
+	assert.NoError(t, err)
+
+	rankFuzzy(conf, newdata)
+
+	got := []string{}
+	for _, row := range newdata.entries {
+		got = append(got, row[0])
+	}
+
+	assert.EqualValues(t, []string{"kube-state", "kube-setup", "kube-prometheus-blackbox-exporter"}, got)
+}
+
+func TestRankFuzzyTightMatch(t *testing.T) {
+	data := Tabdata{
+		headers: []string{"NAME", "STATUS"},
+		entries: [][]string{
+			{"kaaasaaat", "Running"},
+			{"kube-state-metrics-exporter-deployment", "Running"},
+		},
+	}
+
+	conf := cfg.Config{UseFuzzySearch: true, FuzzyRank: true}
+	assert.NoError(t, conf.PreparePattern([]*cfg.Pattern{{Pattern: "kst"}}))
+
+	rankFuzzy(conf, &data)
+
+	assert.EqualValues(t, "kube-state-metrics-exporter-deployment", data.entries[0][0])
+	assert.EqualValues(t, "kaaasaaat", data.entries[1][0])
+}
+
+func TestFuzzySkipped(t *testing.T) {
+	var input = []struct {
+		term   string
+		value  string
+		expect int
+	}{
+		{"kst", "kube-state", 4},
+		{"kst", "kaaasaaat kst", 0},
+		{"KST", "kube-State", 4},
+		{"kst", "kube", -1},
+		{"", "kube", 0},
+	}
+
+	for _, inputdata := range input {
+		testname := fmt.Sprintf("fuzzy-skipped-%s-%s", inputdata.term, inputdata.value)
+
+		t.Run(testname, func(t *testing.T) {
+			assert.EqualValues(t, inputdata.expect, fuzzySkipped(inputdata.term, inputdata.value))
+		})
+	}
+}
+
+func TestFuzzyHighlight(t *testing.T) {
+	style := color.New(color.Red)
+
+	var input = []struct {
+		terms  []string
+		text   string
+		expect string
+	}{
+		{[]string{"kst"}, "kube-state", style.Sprint("k") + "ube-" + style.Sprint("s") + style.Sprint("t") + "ate"},
+		{[]string{"xyz"}, "kube-state", "kube-state"},
+		{[]string{"kb", "ate"}, "kube-state",
+			style.Sprint("k") + "u" + style.Sprint("b") + "e-st" + style.Sprint("a") +
+				style.Sprint("t") + style.Sprint("e")},
+	}
+
+	for _, inputdata := range input {
+		testname := fmt.Sprintf("fuzzy-highlight-%s", strings.Join(inputdata.terms, ","))
+
+		t.Run(testname, func(t *testing.T) {
+			assert.EqualValues(t, inputdata.expect, fuzzyHighlight(inputdata.terms, inputdata.text, style))
+		})
+	}
+}
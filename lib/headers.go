@@ -0,0 +1,122 @@
+/*
+Copyright © 2025 Thomas von Dein
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package lib
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/tlinden/tablizer/cfg"
+)
+
+var (
+	// a lower case letter or digit followed by an upper case one
+	camelRe = regexp.MustCompile(`([a-z0-9])([A-Z])`)
+
+	// everything which is not allowed in snake case identifiers
+	nonSnakeRe = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+/*
+Rename headers as given with --rename col=name, then apply the
+--header-transform to all headers which have not been renamed. This
+happens right after parsing, so all other options can use the new
+names.
+*/
+func RenameHeaders(conf cfg.Config, data *Tabdata) error {
+	if len(conf.Renames) == 0 && conf.HeaderTransform == "" {
+		return nil
+	}
+
+	headers := slices.Clone(data.headers)
+	renamed := make([]bool, len(headers))
+
+	for _, rename := range conf.Renames {
+		col, err := renameColumn(rename.Column, data)
+		if err != nil {
+			return err
+		}
+
+		headers[col] = rename.Name
+		renamed[col] = true
+	}
+
+	for idx, header := range headers {
+		if !renamed[idx] {
+			headers[idx] = transformHeader(conf, header)
+		}
+	}
+
+	data.headers = headers
+	data.maxwidthHeader = 0
+
+	for _, header := range headers {
+		data.maxwidthHeader = max(data.maxwidthHeader, len(header))
+	}
+
+	return nil
+}
+
+// resolve the column of a rename by number or original header name
+func renameColumn(column string, data *Tabdata) (int, error) {
+	if num, err := strconv.Atoi(column); err == nil {
+		if num < 1 || num > len(data.headers) {
+			return 0, fmt.Errorf("rename column %d does not exist", num)
+		}
+
+		return num - 1, nil
+	}
+
+	for idx, header := range data.headers {
+		if strings.EqualFold(header, column) {
+			return idx, nil
+		}
+	}
+
+	return 0, fmt.Errorf("rename column %s does not exist", column)
+}
+
+func transformHeader(conf cfg.Config, header string) string {
+	switch conf.HeaderTransform {
+	case "":
+		return header
+	case "lower":
+		return strings.ToLower(header)
+	case "upper":
+		return strings.ToUpper(header)
+	case "snake":
+		return snakeCase(header)
+	}
+
+	if conf.HeaderRegex != nil {
+		return conf.HeaderRegex.Search.ReplaceAllString(header, conf.HeaderRegex.Replace)
+	}
+
+	return header
+}
+
+// convert a header like "NOMINATED NODE", "CPU(cores)" or "restartCount"
+// into snake case: nominated_node, cpu_cores, restart_count
+func snakeCase(header string) string {
+	header = strings.ToLower(camelRe.ReplaceAllString(header, "${1}_${2}"))
+
+	return strings.Trim(nonSnakeRe.ReplaceAllString(header, "_"), "_")
+}
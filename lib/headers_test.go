@@ -0,0 +1,107 @@
+/*
+Copyright © 2025 Thomas von Dein
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package lib
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tlinden/tablizer/cfg"
+)
+
+func TestSnakeCase(t *testing.T) {
+	var tests = []struct {
+		header string
+		expect string
+	}{
+		{"NAME", "name"},
+		{"NOMINATED NODE", "nominated_node"},
+		{"CPU(cores)", "cpu_cores"},
+		{"restartCount", "restart_count"},
+		{"  %used-space ", "used_space"},
+	}
+
+	for _, testdata := range tests {
+		testname := fmt.Sprintf("snakecase-%s", testdata.header)
+
+		t.Run(testname, func(t *testing.T) {
+			assert.EqualValues(t, testdata.expect, snakeCase(testdata.header))
+		})
+	}
+}
+
+func TestRenameHeaders(t *testing.T) {
+	var tests = []struct {
+		renames   []cfg.Rename
+		transform string
+		regex     *cfg.Transposer
+		expect    []string
+		wanterror bool
+	}{
+		{
+			renames: []cfg.Rename{{Column: "cpu(cores)", Name: "cpu"}, {Column: "3", Name: "node"}},
+			expect:  []string{"NAME", "cpu", "node"},
+		},
+		{
+			renames:   []cfg.Rename{{Column: "cpu(cores)", Name: "cpu"}},
+			transform: "upper",
+			expect:    []string{"NAME", "cpu", "NOMINATED NODE"},
+		},
+		{
+			transform: "snake",
+			expect:    []string{"name", "cpu_cores", "nominated_node"},
+		},
+		{
+			transform: "/\\(.*\\)|\\s//",
+			regex:     &cfg.Transposer{Search: *regexp.MustCompile(`\(.*\)|\s`)},
+			expect:    []string{"NAME", "CPU", "NOMINATEDNODE"},
+		},
+		{
+			renames:   []cfg.Rename{{Column: "cpu", Name: "x"}},
+			wanterror: true,
+		},
+		{
+			renames:   []cfg.Rename{{Column: "4", Name: "x"}},
+			wanterror: true,
+		},
+	}
+
+	for _, testdata := range tests {
+		testname := fmt.Sprintf("rename-%v-%s", testdata.renames, testdata.transform)
+
+		t.Run(testname, func(t *testing.T) {
+			data := Tabdata{headers: []string{"NAME", "CPU(cores)", "NOMINATED NODE"}}
+			conf := cfg.Config{
+				Renames:         testdata.renames,
+				HeaderTransform: testdata.transform,
+				HeaderRegex:     testdata.regex,
+			}
+
+			err := RenameHeaders(conf, &data)
+
+			if testdata.wanterror {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.EqualValues(t, testdata.expect, data.headers)
+			}
+		})
+	}
+}
@@ -20,6 +20,7 @@ package lib
 import (
 	"errors"
 	"fmt"
+	"math/rand/v2"
 	"os"
 	"regexp"
 	"slices"
@@ -30,6 +31,9 @@ import (
 	"github.com/tlinden/tablizer/cfg"
 )
 
+// ANSI color escape sequence, as written by colorizing
+var escapeRe = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
 func findindex(s []int, e int) (int, bool) {
 	for i, a := range s {
 		if a == e {
@@ -54,6 +58,55 @@ func ValidateConsistency(data *Tabdata) error {
 	return nil
 }
 
+/*
+Apply the --on-error policy to a row  with an unexpected number of
+fields. Returns  the fixed row and  false if the row  shall be
+skipped. Diagnostics are written to STDERR using the original input
+line number.
+*/
+func fixRow(conf cfg.Config, data *Tabdata, row []string, lineno int, glue string) ([]string, bool, error) {
+	expected := len(data.headers)
+	got := len(row)
+
+	if got == expected {
+		return row, true, nil
+	}
+
+	var action string
+
+	switch {
+	case failOnError(conf):
+		return nil, false, fmt.Errorf("line %d contains %d fields, but %d are expected",
+			lineno, got, expected)
+	case conf.OnError == "skip":
+		action = "skipped"
+	case got < expected:
+		for i := got; i < expected; i++ {
+			row = append(row, "")
+		}
+
+		action = "padded"
+	case conf.OnError == "pad":
+		row = row[:expected]
+		action = "truncated"
+	default:
+		row = append(row[:expected-1], strings.Join(row[expected-1:], glue))
+		action = "merged"
+	}
+
+	fmt.Fprintf(os.Stderr, "line %d: expected %d fields but got %d, %s\n",
+		lineno, expected, got, action)
+
+	data.malformed++
+
+	return row, action != "skipped", nil
+}
+
+// true if malformed rows shall abort processing (the default)
+func failOnError(conf cfg.Config) bool {
+	return conf.OnError == "" || conf.OnError == "fail"
+}
+
 // parse columns list given  with -c, modifies config.UseColumns based
 // on eventually given regex.
 // This is an output filter, because -cN,N,... is being applied AFTER
@@ -75,11 +128,32 @@ func PrepareColumns(conf *cfg.Config, data *Tabdata) error {
 
 	conf.UseYankColumns = useyankcolumns
 
+	// --unique columns
+	useuniquecolumns, err := PrepareColumnVars(conf.Unique, data)
+	if err != nil {
+		return err
+	}
+
+	for _, col := range useuniquecolumns {
+		if col < 1 || col > len(data.headers) {
+			return fmt.Errorf("unique column %d does not exist", col)
+		}
+	}
+
+	conf.UseUniqueColumns = useuniquecolumns
+
 	return nil
 }
 
-// Same thing as above but for -T option, which is an input option,
-// because transposers are being applied before output.
+/*
+Same thing as above but for -T option, which is an input option,
+because transposers are being applied before output. Transposers
+without column prefix apply to the -T columns: if there is only one
+column, all of them are chained, otherwise the number of columns and
+transposers must match. Afterwards UseTransposeColumns contains the
+column for every transposer in UseTransposers, a column may appear
+multiple times.
+*/
 func PrepareTransposerColumns(conf *cfg.Config, data *Tabdata) error {
 	// -T columns
 	usetransposecolumns, err := PrepareColumnVars(conf.TransposeColumns, data)
@@ -87,13 +161,62 @@ func PrepareTransposerColumns(conf *cfg.Config, data *Tabdata) error {
 		return err
 	}
 
-	conf.UseTransposeColumns = usetransposecolumns
-
-	// verify that columns and transposers match and prepare transposer structs
+	// parse transposers into Transposer structs
 	if err := conf.PrepareTransposers(); err != nil {
 		return err
 	}
 
+	unprefixed := 0
+
+	for _, transposer := range conf.UseTransposers {
+		if transposer.Columns == "" {
+			unprefixed++
+		}
+	}
+
+	mismatch := unprefixed > 0 && len(usetransposecolumns) != 1 && unprefixed != len(usetransposecolumns)
+	if mismatch || (conf.TransposeColumns != "" && unprefixed == 0) {
+		return fmt.Errorf("the number of transposers needs to correspond to the number of transpose columns: %d != %d",
+			unprefixed, len(usetransposecolumns))
+	}
+
+	transposers := []cfg.Transposer{}
+	columns := []int{}
+	pos := 0
+
+	for _, transposer := range conf.UseTransposers {
+		if transposer.Columns == "" {
+			col := usetransposecolumns[0]
+			if len(usetransposecolumns) > 1 {
+				col = usetransposecolumns[pos]
+			}
+
+			pos++
+
+			transposers = append(transposers, transposer)
+			columns = append(columns, col)
+
+			continue
+		}
+
+		prefixcolumns, err := PrepareColumnVars(transposer.Columns, data)
+		if err != nil {
+			return err
+		}
+
+		if len(prefixcolumns) == 0 {
+			return fmt.Errorf("transposer column %s does not exist", transposer.Columns)
+		}
+
+		for _, col := range prefixcolumns {
+			transposers = append(transposers, transposer)
+			columns = append(columns, col)
+		}
+	}
+
+	conf.UseTransposers = transposers
+	conf.UseTransposeColumns = columns
+
 	return nil
 }
 
@@ -242,9 +365,242 @@ func reduceColumns(conf cfg.Config, data *Tabdata) {
 		}
 
 		data.entries = reducedEntries
+
+		if len(data.types) > 0 {
+			reducedTypes := make([]int, len(conf.UseColumns))
+			for idx, col := range conf.UseColumns {
+				reducedTypes[idx] = data.columnType(col - 1)
+			}
+
+			data.types = reducedTypes
+		}
 	}
 }
 
+/*
+Only keep one row per unique combination of the --unique columns,
+the first one or the last one with --unique-last. The kept rows stay
+at their position. With --unique-count a COUNT column is added.
+*/
+func uniqueRows(conf *cfg.Config, data *Tabdata) {
+	if len(conf.UseUniqueColumns) == 0 {
+		return
+	}
+
+	keys := map[string]int{} // key => index into rows
+	counts := []int{}
+	rows := []int{} // indices of the rows to keep
+
+	// look  at the rows  backwards with --unique-last,  so that the
+	// last row of a combination is being found first
+	order := make([]int, len(data.entries))
+	for idx := range order {
+		order[idx] = idx
+	}
+
+	if conf.UniqueLast {
+		slices.Reverse(order)
+	}
+
+	for _, rowidx := range order {
+		row := data.entries[rowidx]
+
+		values := make([]string, len(conf.UseUniqueColumns))
+		for idx, col := range conf.UseUniqueColumns {
+			if col <= len(row) {
+				values[idx] = row[col-1]
+			}
+		}
+
+		// use a separator which can't be part of the values
+		key := strings.Join(values, "\x00")
+
+		pos, exists := keys[key]
+		if !exists {
+			keys[key] = len(rows)
+			rows = append(rows, rowidx)
+			counts = append(counts, 1)
+
+			continue
+		}
+
+		counts[pos]++
+	}
+
+	if conf.UniqueLast {
+		slices.Reverse(rows)
+		slices.Reverse(counts)
+	}
+
+	data.reorderRows(rows)
+
+	if conf.UniqueCount {
+		for idx, row := range data.entries {
+			data.entries[idx] = append(slices.Clone(row), strconv.Itoa(counts[idx]))
+		}
+
+		addColumn(conf, data, "COUNT", cfg.TypeInt)
+	}
+}
+
+/*
+Fill empty cells of the columns given with --fill-down with the last
+non-empty value above, used for hierarchical output like lsblk or
+exports of merged cells. Applied before filtering and sorting.
+*/
+func FillDown(conf cfg.Config, data *Tabdata) (*Tabdata, bool, error) {
+	if conf.FillDown == "" {
+		return nil, false, nil
+	}
+
+	columns, err := PrepareColumnVars(conf.FillDown, data)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if len(columns) == 0 {
+		return nil, false, fmt.Errorf("fill-down column %s does not exist", conf.FillDown)
+	}
+
+	newdata := data.CloneEmpty()
+	last := make([]string, len(columns))
+
+	for _, row := range data.entries {
+		row = slices.Clone(row)
+
+		for idx, col := range columns {
+			if col < 1 || col > len(data.headers) {
+				continue
+			}
+
+			for len(row) < col {
+				row = append(row, "")
+			}
+
+			if strings.TrimSpace(row[col-1]) == "" {
+				row[col-1] = last[idx]
+			} else {
+				last[idx] = row[col-1]
+			}
+		}
+
+		newdata.entries = append(newdata.entries, row)
+	}
+
+	// types may differ now that empty cells have been filled
+	newdata.types = slices.Clone(newdata.types)
+
+	for _, col := range columns {
+		if col >= 1 && col <= len(newdata.types) {
+			if err := reinferType(conf, &newdata, col-1); err != nil {
+				return nil, false, err
+			}
+		}
+	}
+
+	return &newdata, true, nil
+}
+
+// register a new column appended to the data, its rows must be filled
+// by the caller. If -c has been used, the new column will be shown too.
+func addColumn(conf *cfg.Config, data *Tabdata, header string, kind int) {
+	// headers and types may be shared with other tables, see CloneEmpty()
+	data.headers = append(slices.Clone(data.headers), header)
+	data.types = append(slices.Clone(data.types), kind)
+	data.columns = len(data.headers)
+
+	if len(header) > data.maxwidthHeader {
+		data.maxwidthHeader = len(header)
+	}
+
+	if len(conf.UseColumns) > 0 {
+		conf.UseColumns = append(slices.Clone(conf.UseColumns), len(data.headers))
+	}
+}
+
+/*
+Reduce the rows  after sorting, applied in this  order: skip --offset
+rows, keep the first --head rows,  keep the last --tail rows and keep
+--sample randomly selected rows, retaining their order.
+*/
+func limitRows(conf cfg.Config, data *Tabdata) {
+	// work on the row indices, so that the context marks follow
+	rows := make([]int, len(data.entries))
+	for idx := range rows {
+		rows[idx] = idx
+	}
+
+	if conf.Offset > 0 {
+		rows = rows[min(conf.Offset, len(rows)):]
+	}
+
+	if conf.Head > 0 && conf.Head < len(rows) {
+		rows = rows[:conf.Head]
+	}
+
+	if conf.Tail > 0 && conf.Tail < len(rows) {
+		rows = rows[len(rows)-conf.Tail:]
+	}
+
+	if conf.Sample > 0 && conf.Sample < len(rows) {
+		seed := conf.Seed
+		if seed == 0 {
+			seed = rand.Uint64()
+		}
+
+		rng := rand.New(rand.NewPCG(seed, seed))
+
+		picked := rng.Perm(len(rows))[:conf.Sample]
+		slices.Sort(picked)
+
+		sample := make([]int, len(picked))
+		for idx, pos := range picked {
+			sample[idx] = rows[pos]
+		}
+
+		rows = sample
+	}
+
+	data.reorderRows(rows)
+}
+
+/*
+Transpose the table: the headers become the first column named FIELD
+and every row becomes a column, named by its number.
+*/
+func rotateTable(conf cfg.Config, data *Tabdata) {
+	if !conf.Rotate {
+		return
+	}
+
+	headers := []string{"FIELD"}
+	for idx := range data.entries {
+		headers = append(headers, strconv.Itoa(idx+1))
+	}
+
+	entries := make([][]string, len(data.headers))
+
+	for col, header := range data.headers {
+		entries[col] = []string{header}
+
+		for _, row := range data.entries {
+			entries[col] = append(entries[col], cell(row, col))
+		}
+	}
+
+	data.headers = headers
+	data.entries = entries
+	data.columns = len(headers)
+	data.maxwidthHeader = 0
+
+	for _, header := range headers {
+		data.maxwidthHeader = max(data.maxwidthHeader, len(header))
+	}
+
+	// every column contains values of different types now
+	inferTypes(data)
+}
+
 // FIXME: refactor this beast!
 func colorizeData(conf cfg.Config, output string) string {
 	switch {
@@ -281,15 +637,35 @@ func colorizeData(conf cfg.Config, output string) string {
 
 		return colorized
 
+	case len(conf.Patterns) > 0 && !conf.NoColor && color.IsConsole(os.Stdout) && conf.UseFuzzySearch:
+		terms := []string{}
+
+		for _, pattern := range conf.Patterns {
+			if !pattern.Negate && pattern.Columns == "" {
+				terms = append(terms, pattern.Pattern)
+			}
+		}
+
+		lines := strings.Split(output, "\n")
+		for idx, line := range lines {
+			lines[idx] = highlightPlain(line, func(text string) string {
+				return fuzzyHighlight(terms, text, conf.ColorStyle)
+			})
+		}
+
+		return strings.Join(lines, "\n")
+
 	case len(conf.Patterns) > 0 && !conf.NoColor && color.IsConsole(os.Stdout):
 		out := output
 
 		for _, re := range conf.Patterns {
-			if !re.Negate {
+			if !re.Negate && re.Columns == "" {
 				r := regexp.MustCompile("(" + re.Pattern + ")")
 
-				out = r.ReplaceAllStringFunc(out, func(in string) string {
-					return conf.ColorStyle.Sprint(in)
+				out = highlightPlain(out, func(text string) string {
+					return r.ReplaceAllStringFunc(text, func(in string) string {
+						return conf.ColorStyle.Sprint(in)
+					})
 				})
 			}
 		}
@@ -300,3 +676,146 @@ func colorizeData(conf cfg.Config, output string) string {
 		return output
 	}
 }
+
+/*
+Mark  context rows,  which have  been added  around pattern  matches by
+FilterRows().
+*/
+func colorizeContext(conf cfg.Config, data *Tabdata) {
+	switch conf.OutputMode {
+	case cfg.ASCII, cfg.Orgtbl, cfg.Markdown, cfg.Extended:
+	default:
+		return
+	}
+
+	if !conf.UseContext() || conf.UseHighlight || conf.NoColor || !color.IsConsole(os.Stdout) {
+		return
+	}
+
+	markContext(conf, data)
+}
+
+func markContext(conf cfg.Config, data *Tabdata) {
+	for pos, context := range data.context {
+		if !context {
+			continue
+		}
+
+		row := data.entries[pos]
+		for idx := range row {
+			row[idx] = conf.ContextStyle.Sprint(row[idx])
+		}
+	}
+}
+
+/*
+Highlight matches of patterns restricted to columns inside those
+columns only. Unrestricted patterns are highlighted by colorizeData()
+on the rendered output.
+*/
+func colorizeColumns(conf cfg.Config, data *Tabdata) {
+	switch conf.OutputMode {
+	case cfg.ASCII, cfg.Orgtbl, cfg.Markdown, cfg.Extended:
+	default:
+		return
+	}
+
+	if !conf.ScopedPatterns() || conf.UseHighlight || conf.NoColor || !color.IsConsole(os.Stdout) {
+		return
+	}
+
+	highlightColumns(conf, data)
+}
+
+func highlightColumns(conf cfg.Config, data *Tabdata) {
+	columns, err := patternColumns(conf, data)
+	if err != nil {
+		// already checked by FilterRows()
+		return
+	}
+
+	if conf.UseFuzzySearch {
+		// collect the terms per column, so that every cell is only
+		// highlighted once
+		terms := map[int][]string{}
+
+		for idx, pattern := range conf.Patterns {
+			if pattern.Negate {
+				continue
+			}
+
+			for _, col := range columns[idx] {
+				terms[col] = append(terms[col], pattern.Pattern)
+			}
+		}
+
+		for _, row := range data.entries {
+			for col, colterms := range terms {
+				if col <= len(row) {
+					row[col-1] = highlightPlain(row[col-1], func(text string) string {
+						return fuzzyHighlight(colterms, text, conf.ColorStyle)
+					})
+				}
+			}
+		}
+
+		return
+	}
+
+	for idx, pattern := range conf.Patterns {
+		if columns[idx] == nil || pattern.Negate {
+			continue
+		}
+
+		re := regexp.MustCompile("(" + pattern.Pattern + ")")
+
+		for _, row := range data.entries {
+			for _, col := range columns[idx] {
+				if col <= len(row) {
+					row[col-1] = highlightPlain(row[col-1], func(text string) string {
+						return re.ReplaceAllStringFunc(text, func(in string) string {
+							return conf.ColorStyle.Sprint(in)
+						})
+					})
+				}
+			}
+		}
+	}
+}
+
+/*
+Apply highlight only to the parts of text which are not colored yet,
+so that  patterns can't match  inside of escape sequences  and text,
+which has already been colored, eg. context rows or matches of other
+patterns, isn't highlighted again.
+*/
+func highlightPlain(text string, highlight func(string) string) string {
+	var out strings.Builder
+
+	colored := false
+	pos := 0
+
+	plain := func(end int) {
+		switch {
+		case pos == end:
+		case colored:
+			out.WriteString(text[pos:end])
+		default:
+			out.WriteString(highlight(text[pos:end]))
+		}
+	}
+
+	for _, loc := range escapeRe.FindAllStringIndex(text, -1) {
+		plain(loc[0])
+
+		escape := text[loc[0]:loc[1]]
+		out.WriteString(escape)
+
+		colored = escape != color.ResetSet
+		pos = loc[1]
+	}
+
+	plain(len(text))
+
+	return out.String()
+}
@@ -164,6 +164,171 @@ func TestPrepareTransposerColumns(t *testing.T) {
 	}
 }
 
+func TestPrepareTransposerChains(t *testing.T) {
+	data := Tabdata{
+		headers: []string{"ONE", "TWO", "THREE"},
+		entries: [][]string{{"2", "3", "4"}},
+	}
+
+	var tests = []struct {
+		input     string
+		transp    []string
+		expcols   []int
+		wanterror bool
+	}{
+		{"1", []string{`/\d/x/`, `upper`}, []int{1, 1}, false},
+		{"", []string{`two:trim`, `T.:/\d/x/`}, []int{2, 2, 3}, false},
+		{"3", []string{`/\d/x/`, `one:truncate(1)`, `lower`}, []int{3, 1, 3}, false},
+		{"", []string{`four:upper`}, nil, true},
+		{"", []string{`one:/[x/y/`}, nil, true},
+		{"", []string{`one:camel`}, nil, true},
+		{"", []string{`truncate`}, nil, true},
+		{"1,2", []string{`upper`, `lower`, `trim`}, nil, true},
+	}
+
+	for _, testdata := range tests {
+		testname := fmt.Sprintf("PrepareTransposerChains-%s-%v", testdata.input, testdata.transp)
+		t.Run(testname, func(t *testing.T) {
+			conf := cfg.Config{TransposeColumns: testdata.input, Transposers: testdata.transp}
+			err := PrepareTransposerColumns(&conf, &data)
+
+			if testdata.wanterror {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.EqualValues(t, testdata.expcols, conf.UseTransposeColumns)
+				assert.EqualValues(t, len(conf.UseTransposeColumns), len(conf.UseTransposers))
+			}
+		})
+	}
+}
+
+func TestTransposeFields(t *testing.T) {
+	data := Tabdata{
+		headers: []string{"NAME", "STATUS"},
+		entries: [][]string{
+			{" grafana-fcc54cbc9 ", "Running"},
+			{"coredns", "Completed"},
+		},
+	}
+
+	conf := cfg.Config{
+		TransposeColumns: "name",
+		Transposers:      []string{`trim`, `/-.*//`, `upper`, `status:truncate(4)`, `status:/Run/RUN/`},
+	}
+
+	assert.NoError(t, PrepareTransposerColumns(&conf, &data))
+
+	newdata, changed, err := TransposeFields(conf, &data)
+
+	assert.NoError(t, err)
+	assert.True(t, changed)
+
+	// no rows must get lost and the original data must be untouched
+	assert.EqualValues(t, [][]string{{"GRAFANA", "RUNn"}, {"COREDNS", "Comp"}}, newdata.entries)
+	assert.EqualValues(t, "Running", data.entries[0][1])
+}
+
+func TestTransposeFieldsConditional(t *testing.T) {
+	data := Tabdata{
+		headers: []string{"NAME", "STATUS", "AGE"},
+		entries: [][]string{
+			{"job-1", "Completed", "11d"},
+			{"job-2", "Running", "2h"},
+			{"job-3", "Completed", "5m"},
+		},
+	}
+
+	inferTypes(&data)
+
+	var tests = []struct {
+		transp    []string
+		expect    []string // AGE column
+		wanterror bool
+	}{
+		{[]string{`age:/^.+$/stale/ if status=Completed`}, []string{"stale", "2h", "stale"}, false},
+		{[]string{`age:/^.+$/stale/ if status=Completed`, `status:/Completed/Done/`}, []string{"stale", "2h", "stale"}, false},
+		{[]string{`age:/^.+$/old/ if age>1h`}, []string{"old", "old", "5m"}, false},
+		{[]string{`age:upper if status!=Completed|age<10m`}, []string{"11d", "2H", "5M"}, false},
+		{[]string{`age:upper if node=x`}, nil, true},
+	}
+
+	for _, testdata := range tests {
+		testname := fmt.Sprintf("TransposeFieldsConditional-%v", testdata.transp)
+		t.Run(testname, func(t *testing.T) {
+			conf := cfg.Config{Transposers: testdata.transp}
+
+			assert.NoError(t, PrepareTransposerColumns(&conf, &data))
+
+			newdata, _, err := TransposeFields(conf, &data)
+
+			if testdata.wanterror {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.EqualValues(t, testdata.expect, columnValues(newdata, 2))
+			}
+		})
+	}
+}
+
+func TestFillDown(t *testing.T) {
+	var tests = []struct {
+		columns   string
+		expect    [][]string
+		wanterror bool
+	}{
+		{
+			"disk",
+			[][]string{{"sda", "sda1", "1G"}, {"sda", "sda2", ""}, {"sdb", "sdb1", "5G"}, {"sdb", "sdb2"}},
+			false,
+		},
+		{
+			"disk,3",
+			[][]string{{"sda", "sda1", "1G"}, {"sda", "sda2", "1G"}, {"sdb", "sdb1", "5G"}, {"sdb", "sdb2", "5G"}},
+			false,
+		},
+		{"nonexistent", nil, true},
+	}
+
+	for _, testdata := range tests {
+		testname := fmt.Sprintf("FillDown-%s", testdata.columns)
+		t.Run(testname, func(t *testing.T) {
+			data := Tabdata{
+				headers: []string{"DISK", "PART", "SIZE"},
+				entries: [][]string{{"sda", "sda1", "1G"}, {"", "sda2", ""}, {"sdb", "sdb1", "5G"}, {" ", "sdb2"}},
+			}
+
+			inferTypes(&data)
+
+			newdata, changed, err := FillDown(cfg.Config{FillDown: testdata.columns}, &data)
+
+			if testdata.wanterror {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.True(t, changed)
+				assert.EqualValues(t, testdata.expect, newdata.entries)
+				assert.EqualValues(t, "", data.entries[1][0])
+			}
+		})
+	}
+
+	// types given with --types are retained
+	data := Tabdata{
+		headers: []string{"DISK", "SIZE"},
+		entries: [][]string{{"sda", "1G"}, {"", ""}},
+	}
+
+	inferTypes(&data)
+
+	conf := cfg.Config{FillDown: "size", Types: map[string]int{"size": cfg.TypeString}}
+	newdata, _, err := FillDown(conf, &data)
+
+	assert.NoError(t, err)
+	assert.EqualValues(t, cfg.TypeString, newdata.types[1])
+}
+
 func TestReduceColumns(t *testing.T) {
 	var tests = []struct {
 		expect  [][]string
@@ -230,3 +395,166 @@ func TestNumberizeHeaders(t *testing.T) {
 		})
 	}
 }
+
+func TestLimitRows(t *testing.T) {
+	var tests = []struct {
+		name   string
+		conf   cfg.Config
+		expect []string
+	}{
+		{"none", cfg.Config{}, []string{"1", "2", "3", "4", "5"}},
+		{"head", cfg.Config{Head: 2}, []string{"1", "2"}},
+		{"tail", cfg.Config{Tail: 2}, []string{"4", "5"}},
+		{"offset", cfg.Config{Offset: 3}, []string{"4", "5"}},
+		{"offset-head", cfg.Config{Offset: 1, Head: 2}, []string{"2", "3"}},
+		{"head-tail", cfg.Config{Head: 4, Tail: 2}, []string{"3", "4"}},
+		{"offset-too-large", cfg.Config{Offset: 10}, []string{}},
+		{"head-too-large", cfg.Config{Head: 10}, []string{"1", "2", "3", "4", "5"}},
+	}
+
+	for _, testdata := range tests {
+		testname := fmt.Sprintf("limit-rows-%s", testdata.name)
+		t.Run(testname, func(t *testing.T) {
+			data := Tabdata{
+				headers: []string{"N"},
+				entries: [][]string{{"1"}, {"2"}, {"3"}, {"4"}, {"5"}},
+			}
+
+			limitRows(testdata.conf, &data)
+
+			got := []string{}
+			for _, row := range data.entries {
+				got = append(got, row[0])
+			}
+
+			assert.EqualValues(t, testdata.expect, got)
+		})
+	}
+}
+
+func TestSampleRows(t *testing.T) {
+	sample := func(seed uint64) [][]string {
+		data := Tabdata{
+			headers: []string{"N"},
+			entries: [][]string{{"1"}, {"2"}, {"3"}, {"4"}, {"5"}, {"6"}, {"7"}, {"8"}},
+		}
+
+		limitRows(cfg.Config{Sample: 3, Seed: seed}, &data)
+
+		return data.entries
+	}
+
+	first := sample(42)
+
+	assert.Len(t, first, 3)
+	assert.EqualValues(t, first, sample(42))
+
+	// the order of the rows is retained
+	assert.True(t, first[0][0] < first[1][0] && first[1][0] < first[2][0])
+}
+
+func TestUniqueRows(t *testing.T) {
+	var tests = []struct {
+		name    string
+		unique  string
+		last    bool
+		count   bool
+		columns []int
+		expect  [][]string
+	}{
+		{
+			name:   "first",
+			unique: "image",
+			expect: [][]string{{"a", "nginx", "1"}, {"b", "redis", "2"}},
+		},
+		{
+			name:   "last",
+			unique: "image",
+			last:   true,
+			expect: [][]string{{"b", "redis", "2"}, {"c", "nginx", "3"}},
+		},
+		{
+			name:    "last-count",
+			unique:  "image",
+			last:    true,
+			count:   true,
+			columns: []int{2},
+			expect:  [][]string{{"b", "redis", "2", "1"}, {"c", "nginx", "3", "2"}},
+		},
+		{
+			name:   "multiple-columns",
+			unique: "image,restarts",
+			expect: [][]string{{"a", "nginx", "1"}, {"b", "redis", "2"}, {"c", "nginx", "3"}},
+		},
+		{
+			name:    "count",
+			unique:  "2",
+			count:   true,
+			columns: []int{2},
+			expect:  [][]string{{"a", "nginx", "1", "2"}, {"b", "redis", "2", "1"}},
+		},
+	}
+
+	for _, testdata := range tests {
+		testname := fmt.Sprintf("unique-rows-%s", testdata.name)
+		t.Run(testname, func(t *testing.T) {
+			data := Tabdata{
+				headers: []string{"NAME", "IMAGE", "RESTARTS"},
+				types:   []int{cfg.TypeString, cfg.TypeString, cfg.TypeInt},
+				entries: [][]string{
+					{"a", "nginx", "1"},
+					{"b", "redis", "2"},
+					{"c", "nginx", "3"},
+				},
+			}
+
+			conf := cfg.Config{
+				Unique:      testdata.unique,
+				UniqueLast:  testdata.last,
+				UniqueCount: testdata.count,
+			}
+
+			assert.NoError(t, PrepareColumns(&conf, &data))
+
+			conf.UseColumns = testdata.columns
+
+			// headers shared with another table, which has room to grow
+			shared := append(make([]string, 0, 4), data.headers...)
+			data.headers = shared
+
+			uniqueRows(&conf, &data)
+
+			assert.EqualValues(t, testdata.expect, data.entries)
+
+			// the shared headers must not be modified
+			assert.EqualValues(t, "", shared[:4][3])
+
+			if testdata.count {
+				assert.EqualValues(t, "COUNT", data.headers[3])
+				assert.EqualValues(t, cfg.TypeInt, data.types[3])
+				assert.EqualValues(t, []int{2, 4}, conf.UseColumns)
+			}
+		})
+	}
+}
+
+func TestRotateTable(t *testing.T) {
+	data := Tabdata{
+		headers: []string{"NAME", "STATUS", "RESTARTS"},
+		entries: [][]string{
+			{"a", "Running", "1"},
+			{"b", "Error"},
+		},
+	}
+
+	rotateTable(cfg.Config{Rotate: true}, &data)
+
+	assert.EqualValues(t, []string{"FIELD", "1", "2"}, data.headers)
+	assert.EqualValues(t, [][]string{
+		{"NAME", "a", "b"},
+		{"STATUS", "Running", "Error"},
+		{"RESTARTS", "1", ""},
+	}, data.entries)
+	assert.EqualValues(t, 3, data.columns)
+	assert.Len(t, data.types, 3)
+}
@@ -22,6 +22,8 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/tlinden/tablizer/cfg"
 )
@@ -35,6 +37,11 @@ func ProcessFiles(conf *cfg.Config, args []string) error {
 		return err
 	}
 
+	// patterns of filter sets used with --use-filter
+	for _, pattern := range conf.SetPatterns {
+		patterns = append(patterns, &cfg.Pattern{Pattern: pattern})
+	}
+
 	if err := conf.PreparePattern(patterns); err != nil {
 		return err
 	}
@@ -58,6 +65,11 @@ func ProcessFiles(conf *cfg.Config, args []string) error {
 		return err
 	}
 
+	err = PrepareFooter(*conf, &data)
+	if err != nil {
+		return err
+	}
+
 	if conf.Interactive {
 		newdata, err := tableEditor(conf, &data)
 		if err != nil {
@@ -67,7 +79,15 @@ func ProcessFiles(conf *cfg.Config, args []string) error {
 		data = *newdata
 	}
 
-	printData(os.Stdout, *conf, &data)
+	err = printData(os.Stdout, *conf, &data)
+	if err != nil {
+		return err
+	}
+
+	if data.malformed > 0 {
+		fmt.Fprintf(os.Stderr, "%d malformed input rows (--on-error=%s)\n",
+			data.malformed, conf.OnError)
+	}
 
 	return nil
 }
@@ -114,3 +134,59 @@ func determineIO(conf *cfg.Config, args []string) (io.Reader, []*cfg.Pattern, er
 
 	return filehandle, patterns, nil
 }
+
+/*
+Read and parse another table from file, used by key filters, mappings
+and joins. Files ending in .json or .csv are parsed accordingly,
+otherwise the given separator is used. Patterns, filters and the like
+are not applied.
+*/
+func loadTable(conf cfg.Config, file, separator string) (Tabdata, error) {
+	fd, err := os.Open(file)
+	if err != nil {
+		return Tabdata{}, fmt.Errorf("failed to read input file %s: %w", file, err)
+	}
+
+	defer func() { _ = fd.Close() }()
+
+	tableconf := cfg.Config{
+		Separator: separator,
+		OnError:   conf.OnError,
+	}
+
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".json":
+		tableconf.InputJSON = true
+	case ".csv":
+		tableconf.Separator = ","
+	}
+
+	data, err := Parse(tableconf, fd)
+	if err != nil {
+		return Tabdata{}, fmt.Errorf("failed to parse %s: %w", file, err)
+	}
+
+	return data, nil
+}
+
+// read a plain list of values, one per line. Empty lines and comments
+// are ignored.
+func loadList(file string) ([]string, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read input file %s: %w", file, err)
+	}
+
+	values := []string{}
+
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		values = append(values, line)
+	}
+
+	return values, nil
+}
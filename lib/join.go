@@ -0,0 +1,177 @@
+/*
+Copyright © 2025 Thomas von Dein
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package lib
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/tlinden/tablizer/cfg"
+)
+
+/*
+Join the  parsed input with the  table given with --join on the key
+columns given with --on left[=right]. The key column of the right
+table is  not added, other  right headers which collide  with left
+ones get the --join-prefix, it is an error if they still collide.
+Depending on --join-type we do an inner, left or full outer join.
+*/
+func JoinTables(conf cfg.Config, data *Tabdata) (*Tabdata, bool, error) {
+	if conf.Join == "" {
+		return nil, false, nil
+	}
+
+	right, err := loadTable(conf, conf.Join, conf.Separator)
+	if err != nil {
+		return nil, false, err
+	}
+
+	leftspec, rightspec, found := strings.Cut(conf.JoinOn, "=")
+	if !found {
+		rightspec = leftspec
+	}
+
+	leftcol, err := joinColumn(leftspec, data, "input")
+	if err != nil {
+		return nil, false, err
+	}
+
+	rightcol, err := joinColumn(rightspec, &right, conf.Join)
+	if err != nil {
+		return nil, false, err
+	}
+
+	newdata := data.CloneEmpty()
+	newdata.headers = slices.Clone(data.headers)
+	newdata.types = nil
+
+	// add right headers except the key column
+	rightcols := []int{}
+
+	for idx, header := range right.headers {
+		if idx == rightcol {
+			continue
+		}
+
+		if joinHeaderExists(newdata.headers, header) {
+			header = conf.JoinPrefix + header
+
+			if joinHeaderExists(newdata.headers, header) {
+				return nil, false, fmt.Errorf("header %s of %s already exists, use another --join-prefix",
+					header, conf.Join)
+			}
+		}
+
+		newdata.headers = append(newdata.headers, header)
+		rightcols = append(rightcols, idx)
+
+		if len(header) > newdata.maxwidthHeader {
+			newdata.maxwidthHeader = len(header)
+		}
+	}
+
+	newdata.columns = len(newdata.headers)
+
+	// index right rows by key
+	index := map[string][]int{}
+	for idx, row := range right.entries {
+		key := strings.TrimSpace(cell(row, rightcol))
+		index[key] = append(index[key], idx)
+	}
+
+	joined := make([]bool, len(right.entries))
+
+	for _, row := range data.entries {
+		matches := index[strings.TrimSpace(cell(row, leftcol))]
+
+		if len(matches) == 0 && conf.JoinType != "inner" {
+			newdata.entries = append(newdata.entries,
+				joinRow(row, nil, len(data.headers), rightcols))
+		}
+
+		for _, match := range matches {
+			joined[match] = true
+			newdata.entries = append(newdata.entries,
+				joinRow(row, right.entries[match], len(data.headers), rightcols))
+		}
+	}
+
+	if conf.JoinType == "full" {
+		// add right rows without a partner, use their key as left key
+		for idx, rightrow := range right.entries {
+			if joined[idx] {
+				continue
+			}
+
+			row := make([]string, len(data.headers))
+			row[leftcol] = cell(rightrow, rightcol)
+
+			newdata.entries = append(newdata.entries,
+				joinRow(row, rightrow, len(data.headers), rightcols))
+		}
+	}
+
+	return &newdata, true, nil
+}
+
+// check if a header already exists, case insensitive like column names
+func joinHeaderExists(headers []string, header string) bool {
+	return slices.ContainsFunc(headers, func(head string) bool {
+		return strings.EqualFold(head, header)
+	})
+}
+
+// resolve a join key column, which must be exactly one column
+func joinColumn(spec string, data *Tabdata, name string) (int, error) {
+	columns, err := PrepareColumnVars(spec, data)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(columns) != 1 || columns[0] < 1 || columns[0] > len(data.headers) {
+		return 0, fmt.Errorf("join column %s must match exactly one column of %s", spec, name)
+	}
+
+	return columns[0] - 1, nil
+}
+
+// combine a left row with the given columns of a right row, which may
+// be nil
+func joinRow(left, right []string, leftlen int, rightcols []int) []string {
+	row := make([]string, 0, leftlen+len(rightcols))
+
+	for idx := range leftlen {
+		row = append(row, cell(left, idx))
+	}
+
+	for _, col := range rightcols {
+		row = append(row, cell(right, col))
+	}
+
+	return row
+}
+
+// return a cell of a row or an empty string if it doesn't exist
+func cell(row []string, idx int) string {
+	if idx < len(row) {
+		return row[idx]
+	}
+
+	return ""
+}
@@ -0,0 +1,128 @@
+/*
+Copyright © 2025 Thomas von Dein
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package lib
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tlinden/tablizer/cfg"
+)
+
+func TestJoinTables(t *testing.T) {
+	top := filepath.Join(t.TempDir(), "top.txt")
+	assert.NoError(t, os.WriteFile(top, []byte(
+		"POD     CPU    STATUS\n"+
+			"pod-a   10m    ok\n"+
+			"pod-c   250m   ok\n"+
+			"pod-x   1m     gone\n"), 0644))
+
+	var tests = []struct {
+		jointype string
+		expect   [][]string
+	}{
+		{
+			"inner",
+			[][]string{
+				{"pod-a", "Running", "10m", "ok"},
+				{"pod-c", "Error", "250m", "ok"},
+			},
+		},
+		{
+			"left",
+			[][]string{
+				{"pod-a", "Running", "10m", "ok"},
+				{"pod-b", "Running", "", ""},
+				{"pod-c", "Error", "250m", "ok"},
+			},
+		},
+		{
+			"full",
+			[][]string{
+				{"pod-a", "Running", "10m", "ok"},
+				{"pod-b", "Running", "", ""},
+				{"pod-c", "Error", "250m", "ok"},
+				{"pod-x", "", "1m", "gone"},
+			},
+		},
+	}
+
+	for _, testdata := range tests {
+		testname := fmt.Sprintf("join-tables-%s", testdata.jointype)
+
+		t.Run(testname, func(t *testing.T) {
+			data := Tabdata{
+				headers: []string{"NAME", "STATUS"},
+				entries: [][]string{
+					{"pod-a", "Running"},
+					{"pod-b", "Running"},
+					{"pod-c", "Error"},
+				},
+			}
+
+			// headers shared with another table, which has room to grow
+			shared := append(make([]string, 0, 4), data.headers...)
+			data.headers = shared
+
+			conf := cfg.Config{
+				Separator:  cfg.SeparatorTemplates[":default:"],
+				Join:       top,
+				JoinOn:     "name=pod",
+				JoinType:   testdata.jointype,
+				JoinPrefix: "right_",
+			}
+
+			newdata, changed, err := JoinTables(conf, &data)
+			assert.NoError(t, err)
+			assert.True(t, changed)
+
+			assert.EqualValues(t, []string{"NAME", "STATUS", "CPU", "right_STATUS"}, newdata.headers)
+			assert.EqualValues(t, testdata.expect, newdata.entries)
+
+			// the shared headers must not be modified
+			assert.EqualValues(t, []string{"", ""}, shared[2:4])
+		})
+	}
+}
+
+func TestJoinTablesErrors(t *testing.T) {
+	data := Tabdata{headers: []string{"NAME"}}
+
+	conf := cfg.Config{
+		Separator: cfg.SeparatorTemplates[":default:"],
+		Join:      filepath.Join(t.TempDir(), "nothere.txt"),
+		JoinOn:    "name",
+	}
+
+	_, _, err := JoinTables(conf, &data)
+	assert.Error(t, err)
+
+	// the prefixed header collides as well
+	right := filepath.Join(t.TempDir(), "right.txt")
+	assert.NoError(t, os.WriteFile(right, []byte("NAME   STATUS\npod-a  ok\n"), 0644))
+
+	data = Tabdata{headers: []string{"NAME", "STATUS", "RIGHT_STATUS"}}
+	conf.Join = right
+	conf.JoinPrefix = "right_"
+
+	_, _, err = JoinTables(conf, &data)
+	assert.ErrorContains(t, err, "already exists")
+}
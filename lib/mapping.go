@@ -0,0 +1,170 @@
+/*
+Copyright © 2025 Thomas von Dein
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package lib
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclsimple"
+	"github.com/tlinden/tablizer/cfg"
+	"gopkg.in/yaml.v3"
+)
+
+/*
+Map column values using dictionaries given with --map col=file. By
+default values are replaced, with --map-add the looked up values are
+added as new column. Values not found in the dictionary are kept, or
+left empty for new columns, unless --map-default has been given.
+*/
+func MapValues(conf cfg.Config, data *Tabdata) (*Tabdata, bool, error) {
+	if len(conf.Maps) == 0 {
+		return nil, false, nil
+	}
+
+	newdata := data.CloneEmpty()
+	newdata.headers = slices.Clone(data.headers)
+	newdata.types = slices.Clone(data.types)
+	newdata.entries = make([][]string, len(data.entries))
+
+	for idx, row := range data.entries {
+		newdata.entries[idx] = slices.Clone(row)
+	}
+
+	for _, mapping := range conf.Maps {
+		dict, valheader, err := loadDictionary(conf, mapping)
+		if err != nil {
+			return nil, false, err
+		}
+
+		col, err := mapColumn(mapping.Column, &newdata)
+		if err != nil {
+			return nil, false, err
+		}
+
+		target := col
+
+		if conf.MapAdd {
+			header := valheader
+			if header == "" {
+				header = newdata.headers[col] + "_mapped"
+			}
+
+			addColumn(&conf, &newdata, header, cfg.TypeString)
+			target = len(newdata.headers) - 1
+		}
+
+		for idx, row := range newdata.entries {
+			value, found := dict[strings.TrimSpace(cell(row, col))]
+
+			switch {
+			case !found && conf.MapDefault != "":
+				value = conf.MapDefault
+			case !found && !conf.MapAdd:
+				value = cell(row, col)
+			}
+
+			for len(row) <= target {
+				row = append(row, "")
+			}
+
+			row[target] = value
+			newdata.entries[idx] = row
+		}
+
+		if err := reinferType(conf, &newdata, target); err != nil {
+			return nil, false, err
+		}
+	}
+
+	return &newdata, true, nil
+}
+
+// resolve the column to be mapped, which must be exactly one
+func mapColumn(spec string, data *Tabdata) (int, error) {
+	columns, err := PrepareColumnVars(spec, data)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(columns) != 1 || columns[0] < 1 || columns[0] > len(data.headers) {
+		return 0, fmt.Errorf("mapping column %s must match exactly one column", spec)
+	}
+
+	return columns[0] - 1, nil
+}
+
+/*
+Read a dictionary. YAML and HCL files must contain plain key value
+pairs, eg "node1: rack-a" or "node1 = "rack-a"". Other files are read
+as table using the first column as key and the second one as value,
+unless key and value columns have been specified. Their first line is
+always the header line, the header of the value column is returned as
+well.
+*/
+func loadDictionary(conf cfg.Config, mapping cfg.ValueMap) (map[string]string, string, error) {
+	dict := map[string]string{}
+
+	switch strings.ToLower(filepath.Ext(mapping.File)) {
+	case ".yaml", ".yml":
+		content, err := os.ReadFile(mapping.File)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read dictionary %s: %w", mapping.File, err)
+		}
+
+		if err := yaml.Unmarshal(content, &dict); err != nil {
+			return nil, "", fmt.Errorf("failed to parse dictionary %s: %w", mapping.File, err)
+		}
+
+		return dict, "", nil
+	case ".hcl":
+		if err := hclsimple.DecodeFile(mapping.File, nil, &dict); err != nil {
+			return nil, "", fmt.Errorf("failed to parse dictionary %s: %w", mapping.File, err)
+		}
+
+		return dict, "", nil
+	}
+
+	table, err := loadTable(conf, mapping.File, cfg.SeparatorTemplates[":default:"])
+	if err != nil {
+		return nil, "", err
+	}
+
+	keycol, valcol := 0, 1
+
+	if mapping.KeyColumn != "" {
+		if keycol, err = mapColumn(mapping.KeyColumn, &table); err != nil {
+			return nil, "", fmt.Errorf("%s: %w", mapping.File, err)
+		}
+
+		if valcol, err = mapColumn(mapping.ValColumn, &table); err != nil {
+			return nil, "", fmt.Errorf("%s: %w", mapping.File, err)
+		}
+	} else if len(table.headers) < 2 {
+		return nil, "", fmt.Errorf("dictionary %s must contain at least two columns", mapping.File)
+	}
+
+	for _, row := range table.entries {
+		dict[strings.TrimSpace(cell(row, keycol))] = cell(row, valcol)
+	}
+
+	return dict, table.headers[valcol], nil
+}
@@ -0,0 +1,127 @@
+/*
+Copyright © 2025 Thomas von Dein
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package lib
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tlinden/tablizer/cfg"
+)
+
+func TestMapValues(t *testing.T) {
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"racks.csv":  "node,rack,room\nn1,rack-a,1\nn2,rack-b,2\n",
+		"racks.yaml": "n1: rack-a\nn2: rack-b\n",
+		"racks.hcl":  "n1 = \"rack-a\"\nn2 = \"rack-b\"\n",
+	}
+
+	for name, content := range files {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+
+	var tests = []struct {
+		mapping  cfg.ValueMap
+		add      bool
+		fallback string
+		headers  []string
+		expect   []string // values of the last column
+	}{
+		{
+			mapping: cfg.ValueMap{Column: "node", File: "racks.csv"},
+			headers: []string{"NAME", "NODE"},
+			expect:  []string{"rack-a", "rack-b", "n3"},
+		},
+		{
+			mapping:  cfg.ValueMap{Column: "node", File: "racks.csv", KeyColumn: "node", ValColumn: "room"},
+			add:      true,
+			fallback: "0",
+			headers:  []string{"NAME", "NODE", "room"},
+			expect:   []string{"1", "2", "0"},
+		},
+		{
+			mapping: cfg.ValueMap{Column: "2", File: "racks.yaml"},
+			add:     true,
+			headers: []string{"NAME", "NODE", "NODE_mapped"},
+			expect:  []string{"rack-a", "rack-b", ""},
+		},
+		{
+			mapping:  cfg.ValueMap{Column: "node", File: "racks.hcl"},
+			fallback: "unknown",
+			headers:  []string{"NAME", "NODE"},
+			expect:   []string{"rack-a", "rack-b", "unknown"},
+		},
+	}
+
+	for _, testdata := range tests {
+		testname := fmt.Sprintf("map-%s-add-%t-default-%s", testdata.mapping.File, testdata.add, testdata.fallback)
+
+		t.Run(testname, func(t *testing.T) {
+			data := Tabdata{
+				headers: []string{"NAME", "NODE"},
+				entries: [][]string{{"pod-a", "n1"}, {"pod-b", "n2"}, {"pod-c", "n3"}},
+			}
+
+			inferTypes(&data)
+
+			mapping := testdata.mapping
+			mapping.File = filepath.Join(dir, mapping.File)
+
+			conf := cfg.Config{Maps: []cfg.ValueMap{mapping}, MapAdd: testdata.add, MapDefault: testdata.fallback}
+
+			newdata, changed, err := MapValues(conf, &data)
+
+			assert.NoError(t, err)
+			assert.True(t, changed)
+			assert.EqualValues(t, testdata.headers, newdata.headers)
+			assert.EqualValues(t, testdata.expect, columnValues(newdata, len(newdata.headers)-1))
+			assert.EqualValues(t, "n1", data.entries[0][1])
+		})
+	}
+}
+
+func TestMapValuesErrors(t *testing.T) {
+	dir := t.TempDir()
+	list := filepath.Join(dir, "list.txt")
+
+	assert.NoError(t, os.WriteFile(list, []byte("NODE\nn1\n"), 0644))
+
+	for _, mapping := range []cfg.ValueMap{
+		{Column: "node", File: filepath.Join(dir, "nonexistent.csv")},
+		{Column: "node", File: list},
+		{Column: "rack", File: list},
+	} {
+		testname := fmt.Sprintf("map-error-%s-%s", mapping.Column, filepath.Base(mapping.File))
+
+		t.Run(testname, func(t *testing.T) {
+			data := Tabdata{headers: []string{"NAME", "NODE"}, entries: [][]string{{"pod-a", "n1"}}}
+			conf := cfg.Config{Maps: []cfg.ValueMap{mapping}, Separator: cfg.SeparatorTemplates[":default:"]}
+
+			inferTypes(&data)
+
+			_, _, err := MapValues(conf, &data)
+
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,197 @@
+/*
+Copyright © 2025 Thomas von Dein
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package lib
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/tlinden/tablizer/cfg"
+)
+
+// a column reference inside a merge template: {name} or {3}
+var mergeRefRe = regexp.MustCompile(`\{([^{}]+)\}`)
+
+/*
+Combine columns into new ones using the templates given with --merge
+name=template, eg ref={namespace}/{name}. Columns are referenced by
+header name (case insensitive) or by number. The new column is
+appended, unless --merge-remove has been given, in which case the
+source columns are removed and the new column takes the place of the
+first of them. If a column with the given name already exists, its
+values will be replaced.
+*/
+func MergeColumns(conf cfg.Config, data *Tabdata) (*Tabdata, bool, error) {
+	if len(conf.Merges) == 0 {
+		return nil, false, nil
+	}
+
+	for _, merge := range conf.Merges {
+		newdata, err := mergeColumn(conf, merge, data)
+		if err != nil {
+			return nil, false, err
+		}
+
+		data = newdata
+	}
+
+	return data, true, nil
+}
+
+// a literal part of a merge template or a column reference
+type mergePart struct {
+	text string
+	col  int // 0-based, -1 for literal text
+}
+
+func mergeColumn(conf cfg.Config, merge cfg.Merge, data *Tabdata) (*Tabdata, error) {
+	sources := []int{}
+	parts := []mergePart{}
+	pos := 0
+
+	// resolve the references once, not for every row
+	for _, match := range mergeRefRe.FindAllStringSubmatchIndex(merge.Template, -1) {
+		col, err := mergeRef(merge.Template[match[2]:match[3]], data)
+		if err != nil {
+			return nil, err
+		}
+
+		if !slices.Contains(sources, col) {
+			sources = append(sources, col)
+		}
+
+		parts = append(parts,
+			mergePart{text: merge.Template[pos:match[0]], col: -1},
+			mergePart{col: col})
+		pos = match[1]
+	}
+
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("merge template %s contains no column references", merge.Template)
+	}
+
+	parts = append(parts, mergePart{text: merge.Template[pos:], col: -1})
+
+	values := make([]string, len(data.entries))
+
+	for idx, row := range data.entries {
+		var value strings.Builder
+
+		for _, part := range parts {
+			if part.col < 0 {
+				value.WriteString(part.text)
+			} else {
+				value.WriteString(cell(row, part.col))
+			}
+		}
+
+		values[idx] = value.String()
+	}
+
+	// where to put the merged values, -1 means append
+	target := slices.IndexFunc(data.headers, func(header string) bool {
+		return strings.EqualFold(header, merge.Name)
+	})
+
+	remove := []int{}
+
+	if conf.MergeRemove {
+		for _, col := range sources {
+			if col != target {
+				remove = append(remove, col)
+			}
+		}
+
+		if target < 0 {
+			target = slices.Min(sources)
+			remove = slices.DeleteFunc(remove, func(col int) bool { return col == target })
+		}
+	}
+
+	newdata := data.CloneEmpty()
+	newdata.headers = []string{}
+	newdata.types = []int{}
+	newdata.entries = make([][]string, len(data.entries))
+
+	// the position of the merged column in the new table
+	mergecol := len(data.headers) - len(remove)
+
+	for col, header := range data.headers {
+		if slices.Contains(remove, col) {
+			continue
+		}
+
+		if col == target {
+			header = merge.Name
+			mergecol = len(newdata.headers)
+		}
+
+		newdata.headers = append(newdata.headers, header)
+		newdata.types = append(newdata.types, data.columnType(col))
+
+		for idx, row := range data.entries {
+			value := cell(row, col)
+			if col == target {
+				value = values[idx]
+			}
+
+			newdata.entries[idx] = append(newdata.entries[idx], value)
+		}
+	}
+
+	if target < 0 {
+		newdata.headers = append(newdata.headers, merge.Name)
+		newdata.types = append(newdata.types, cfg.TypeString)
+
+		for idx := range newdata.entries {
+			newdata.entries[idx] = append(newdata.entries[idx], values[idx])
+		}
+	}
+
+	newdata.columns = len(newdata.headers)
+	newdata.maxwidthHeader = max(newdata.maxwidthHeader, len(merge.Name))
+
+	if err := reinferType(conf, &newdata, mergecol); err != nil {
+		return nil, err
+	}
+
+	return &newdata, nil
+}
+
+// resolve a template reference by column number or header name,
+// returns the 0-based column
+func mergeRef(ref string, data *Tabdata) (int, error) {
+	if num, err := strconv.Atoi(ref); err == nil {
+		if num < 1 || num > len(data.headers) {
+			return 0, fmt.Errorf("merge column %d does not exist", num)
+		}
+
+		return num - 1, nil
+	}
+
+	for idx, header := range data.headers {
+		if strings.EqualFold(header, ref) {
+			return idx, nil
+		}
+	}
+
+	return 0, fmt.Errorf("merge column %s does not exist", ref)
+}
@@ -0,0 +1,121 @@
+/*
+Copyright © 2025 Thomas von Dein
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package lib
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tlinden/tablizer/cfg"
+)
+
+func newMergeData() Tabdata {
+	data := Tabdata{
+		headers: []string{"NAMESPACE", "NAME", "AGE"},
+		entries: [][]string{
+			{"kube-system", "coredns", "11d"},
+			{"default", "nginx", "2h"},
+		},
+	}
+
+	inferTypes(&data)
+
+	return data
+}
+
+func TestMergeColumns(t *testing.T) {
+	var tests = []struct {
+		name     string
+		template string
+		remove   bool
+		headers  []string
+		entries  [][]string
+	}{
+		{
+			name:     "ref",
+			template: "{namespace}/{name}",
+			headers:  []string{"NAMESPACE", "NAME", "AGE", "ref"},
+			entries: [][]string{
+				{"kube-system", "coredns", "11d", "kube-system/coredns"},
+				{"default", "nginx", "2h", "default/nginx"},
+			},
+		},
+		{
+			name:     "ref",
+			template: "{2}.{1}",
+			remove:   true,
+			headers:  []string{"ref", "AGE"},
+			entries:  [][]string{{"coredns.kube-system", "11d"}, {"nginx.default", "2h"}},
+		},
+		{
+			name:     "name",
+			template: "{name} ({age})",
+			remove:   true,
+			headers:  []string{"NAMESPACE", "name"},
+			entries:  [][]string{{"kube-system", "coredns (11d)"}, {"default", "nginx (2h)"}},
+		},
+	}
+
+	for _, testdata := range tests {
+		testname := fmt.Sprintf("merge-%s-%s-remove-%t", testdata.name, testdata.template, testdata.remove)
+
+		t.Run(testname, func(t *testing.T) {
+			data := newMergeData()
+			conf := cfg.Config{
+				MergeRemove: testdata.remove,
+				Merges:      []cfg.Merge{{Name: testdata.name, Template: testdata.template}},
+			}
+
+			newdata, changed, err := MergeColumns(conf, &data)
+
+			assert.NoError(t, err)
+			assert.True(t, changed)
+			assert.EqualValues(t, testdata.headers, newdata.headers)
+			assert.EqualValues(t, testdata.entries, newdata.entries)
+			assert.EqualValues(t, len(testdata.headers), len(newdata.types))
+		})
+	}
+}
+
+func TestMergeColumnsReplace(t *testing.T) {
+	data := newMergeData()
+	conf := cfg.Config{Merges: []cfg.Merge{{Name: "Age", Template: "{age}"}}}
+
+	newdata, changed, err := MergeColumns(conf, &data)
+
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	assert.EqualValues(t, []string{"NAMESPACE", "NAME", "Age"}, newdata.headers)
+	assert.EqualValues(t, cfg.TypeDuration, newdata.types[2])
+}
+
+func TestMergeColumnsErrors(t *testing.T) {
+	for _, template := range []string{"{nonexistent}", "{4}", "{}"} {
+		testname := fmt.Sprintf("merge-error-%s", template)
+
+		t.Run(testname, func(t *testing.T) {
+			data := newMergeData()
+			conf := cfg.Config{Merges: []cfg.Merge{{Name: "x", Template: template}}}
+
+			_, _, err := MergeColumns(conf, &data)
+
+			assert.Error(t, err)
+		})
+	}
+}
@@ -53,7 +53,28 @@ func Parse(conf cfg.Config, input io.Reader) (Tabdata, error) {
 		return data, err
 	}
 
-	// 2nd step, apply filters, code or transposers, if any
+	// join with another table, if any
+	joineddata, changed, err := JoinTables(conf, &data)
+	if err != nil {
+		return data, fmt.Errorf("failed to join tables: %w", err)
+	}
+
+	if changed {
+		data = *joineddata
+	}
+
+	// rename headers, if demanded, so that other options can use the
+	// new names
+	if err := RenameHeaders(conf, &data); err != nil {
+		return data, err
+	}
+
+	// determine column types, used by sorting, filters and output
+	if err := InferTypes(conf, &data); err != nil {
+		return data, err
+	}
+
+	// 3rd step, apply filters, code or transposers, if any
 	postdata, changed, err := PostProcess(conf, &data)
 	if err != nil {
 		return data, err
@@ -118,31 +139,50 @@ func parseCSV(conf cfg.Config, input io.Reader) (Tabdata, error) {
 	csvreader := csv.NewReader(content)
 	csvreader.Comma = rune(conf.Separator[0])
 
-	records, err := csvreader.ReadAll()
-	if err != nil {
-		return data, fmt.Errorf("could not parse CSV input: %w", err)
-	}
+	// we check the number of fields ourselves, see fixRow()
+	csvreader.FieldsPerRecord = -1
 
-	if len(records) >= 1 {
-		data.headers = SetHeaders(conf, records[0])
-		data.columns = len(records)
+	hadFirst := false
 
-		for _, head := range data.headers {
-			// register widest header field
-			headerlen := len(head)
-			if headerlen > data.maxwidthHeader {
-				data.maxwidthHeader = headerlen
-			}
+	for {
+		record, err := csvreader.Read()
+		if err == io.EOF {
+			break
 		}
 
-		if len(records) >= 1 {
-			if conf.AutoHeaders || len(conf.CustomHeaders) > 0 {
-				data.entries = records
-			} else {
-				data.entries = records[1:]
+		if err != nil {
+			return data, fmt.Errorf("could not parse CSV input: %w", err)
+		}
+
+		lineno, _ := csvreader.FieldPos(0)
+
+		if !hadFirst {
+			data.headers = SetHeaders(conf, record)
+			data.columns = len(data.headers)
+
+			for _, head := range data.headers {
+				// register widest header field
+				headerlen := len(head)
+				if headerlen > data.maxwidthHeader {
+					data.maxwidthHeader = headerlen
+				}
 			}
+
+			hadFirst = true
+
+			if !conf.AutoHeaders && len(conf.CustomHeaders) == 0 {
+				continue
+			}
+		}
+
+		row, keep, err := fixRow(conf, &data, record, lineno, conf.Separator)
+		if err != nil {
+			return data, err
 		}
 
+		if keep {
+			data.entries = append(data.entries, row)
+		}
 	}
 
 	return data, nil
@@ -158,10 +198,13 @@ func parseTabular(conf cfg.Config, input io.Reader) (Tabdata, error) {
 
 	hadFirst := false
 	separate := regexp.MustCompile(conf.Separator)
+	lineno := 0
 
 	scanner = bufio.NewScanner(input)
 
 	for scanner.Scan() {
+		lineno++
+
 		line := strings.TrimSpace(scanner.Text())
 		parts := separate.Split(line, -1)
 
@@ -190,7 +233,7 @@ func parseTabular(conf cfg.Config, input io.Reader) (Tabdata, error) {
 
 			if conf.AutoHeaders || len(conf.CustomHeaders) > 0 {
 				// we do not use generated headers, consider as row
-				if matchPattern(conf, line) == conf.InvertMatch {
+				if !keepLine(conf, line) {
 					continue
 				}
 
@@ -198,7 +241,7 @@ func parseTabular(conf cfg.Config, input io.Reader) (Tabdata, error) {
 			}
 		} else {
 			// data processing
-			if matchPattern(conf, line) == conf.InvertMatch {
+			if !keepLine(conf, line) {
 				// by default  -v is false, so if a  line does NOT
 				// match the pattern, we will ignore it. However,
 				// if the user specified -v, the matching is inverted,
@@ -217,12 +260,21 @@ func parseTabular(conf cfg.Config, input io.Reader) (Tabdata, error) {
 				idx++
 			}
 
-			// fill up missing fields, if any
+			// fill up missing fields, if any. Short rows are not
+			// considered to be malformed, regardless of the policy
 			for i := len(values); i < len(data.headers); i++ {
 				values = append(values, "")
 			}
 
-			data.entries = append(data.entries, values)
+			// apply --on-error policy to rows with excess fields
+			values, keep, err := fixRow(conf, &data, values, lineno, " ")
+			if err != nil {
+				return data, err
+			}
+
+			if keep {
+				data.entries = append(data.entries, values)
+			}
 		}
 	}
 
@@ -350,7 +402,7 @@ func parseJSON(conf cfg.Config, input io.Reader) (Tabdata, error) {
 	for _, row := range data.entries {
 		line = strings.Join(row, " ")
 
-		if matchPattern(conf, line) == conf.InvertMatch {
+		if !keepLine(conf, line) {
 			continue
 		}
 
@@ -367,10 +419,77 @@ func parseJSON(conf cfg.Config, input io.Reader) (Tabdata, error) {
 func PostProcess(conf cfg.Config, data *Tabdata) (*Tabdata, bool, error) {
 	var modified bool
 
-	// filter by field filters, if any
-	filtereddata, changed, err := FilterByFields(conf, data)
+	// fill empty cells from above, if demanded
+	filleddata, changed, err := FillDown(conf, data)
+	if err != nil {
+		return data, false, fmt.Errorf("failed to fill down: %w", err)
+	}
+
+	if changed {
+		data = filleddata
+		modified = true
+	}
+
+	// split columns by regexp, if any
+	splitdata, changed, err := SplitColumns(conf, data)
+	if err != nil {
+		return data, false, fmt.Errorf("failed to split columns: %w", err)
+	}
+
+	if changed {
+		data = splitdata
+		modified = true
+	}
+
+	// map values using dictionaries, if any
+	mappeddata, changed, err := MapValues(conf, data)
 	if err != nil {
-		return data, false, fmt.Errorf("failed to filter fields: %w", err)
+		return data, false, fmt.Errorf("failed to map values: %w", err)
+	}
+
+	if changed {
+		data = mappeddata
+		modified = true
+	}
+
+	// add computed columns, if any
+	computeddata, changed, err := AddColumns(conf, data)
+	if err != nil {
+		return data, false, fmt.Errorf("failed to add computed columns: %w", err)
+	}
+
+	if changed {
+		data = computeddata
+		modified = true
+	}
+
+	// merge columns using templates, if any
+	mergeddata, changed, err := MergeColumns(conf, data)
+	if err != nil {
+		return data, false, fmt.Errorf("failed to merge columns: %w", err)
+	}
+
+	if changed {
+		data = mergeddata
+		modified = true
+	}
+
+	// filter by key sets, if any
+	filtereddata, changed, err := FilterByKeys(conf, data)
+	if err != nil {
+		return data, false, fmt.Errorf("failed to filter by keys: %w", err)
+	}
+
+	if changed {
+		data = filtereddata
+		modified = true
+	}
+
+	// filter by patterns restricted to columns, field filters and
+	// expressions, if any
+	filtereddata, changed, err = FilterRows(conf, data)
+	if err != nil {
+		return data, false, fmt.Errorf("failed to filter rows: %w", err)
 	}
 
 	if changed {
@@ -394,6 +513,50 @@ func PostProcess(conf cfg.Config, data *Tabdata) (*Tabdata, bool, error) {
 		modified = true
 	}
 
+	// group and aggregate, if demanded
+	groupeddata, changed, err := GroupRows(conf, data)
+	if err != nil {
+		return data, false, fmt.Errorf("failed to aggregate: %w", err)
+	}
+
+	if changed {
+		data = groupeddata
+		modified = true
+	}
+
+	// turn wide tables into key/value rows, if demanded
+	reshapeddata, changed, err := UnpivotTable(conf, data)
+	if err != nil {
+		return data, false, fmt.Errorf("failed to unpivot: %w", err)
+	}
+
+	if changed {
+		data = reshapeddata
+		modified = true
+	}
+
+	// turn long data into a matrix, if demanded
+	reshapeddata, changed, err = PivotTable(conf, data)
+	if err != nil {
+		return data, false, fmt.Errorf("failed to pivot: %w", err)
+	}
+
+	if changed {
+		data = reshapeddata
+		modified = true
+	}
+
+	// replace the table by its statistics, if demanded
+	statsdata, changed, err := StatsTable(conf, data)
+	if err != nil {
+		return data, false, fmt.Errorf("failed to calculate statistics: %w", err)
+	}
+
+	if changed {
+		data = statsdata
+		modified = true
+	}
+
 	if conf.Debug {
 		repr.Print(data)
 	}
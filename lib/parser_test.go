@@ -57,6 +57,7 @@ func TestParser(t *testing.T) {
 		headers: []string{
 			"ONE", "TWO", "THREE",
 		},
+		types: []int{cfg.TypeString, cfg.TypeString, cfg.TypeString},
 		entries: [][]string{
 			{"asd", "igig", "cxxxncnc"},
 			{"19191", "EDD 1", "X"},
@@ -136,6 +137,7 @@ func TestParserIncompleteRows(t *testing.T) {
 		headers: []string{
 			"ONE", "TWO", "THREE",
 		},
+		types: []int{cfg.TypeString, cfg.TypeString, cfg.TypeString},
 		entries: [][]string{
 			{"asd", "igig", ""},
 			{"19191", "EDD 1", "X"},
@@ -198,6 +200,8 @@ func TestParserJSONInput(t *testing.T) {
 			expect: Tabdata{
 				columns: 7,
 				headers: []string{"NAME", "READY", "STATUS", "RESTARTS", "AGE", "X", "Y"},
+				types: []int{cfg.TypeString, cfg.TypeString, cfg.TypeString,
+					cfg.TypeInt, cfg.TypeString, cfg.TypeInt, cfg.TypeFloat},
 				entries: [][]string{
 					[]string{
 						"postgres-operator-7f4c7c8485-ntlns",
@@ -235,6 +239,8 @@ func TestParserJSONInput(t *testing.T) {
 			expect: Tabdata{
 				columns: 5,
 				headers: []string{"NAME", "READY", "STATUS", "RESTARTS", "AGE"},
+				types: []int{cfg.TypeString, cfg.TypeString, cfg.TypeString,
+					cfg.TypeInt, cfg.TypeDuration},
 				entries: [][]string{
 					[]string{
 						"postgres-operator-7f4c7c8485-ntlns",
@@ -276,6 +282,8 @@ func TestParserJSONInput(t *testing.T) {
 			expect: Tabdata{
 				columns: 5,
 				headers: []string{"NAME", "READY", "STATUS", "RESTARTS", "AGE"},
+				types: []int{cfg.TypeString, cfg.TypeString, cfg.TypeString,
+					cfg.TypeInt, cfg.TypeDuration},
 				entries: [][]string{
 					[]string{
 						"postgres-operator-7f4c7c8485-ntlns",
@@ -427,3 +435,66 @@ func wrapValidateParser(conf cfg.Config, input io.Reader) (Tabdata, error) {
 
 	return data, err
 }
+
+func TestParserOnError(t *testing.T) {
+	table := `
+ONE    TWO    THREE
+asd    igig   cxxxncnc   extra
+19191  EDD 1
+x      y      z`
+
+	var tests = []struct {
+		policy    string
+		separator string
+		expect    [][]string
+		wanterror bool
+	}{
+		{
+			policy:    "fail",
+			wanterror: true,
+		},
+		{
+			policy: "skip",
+			expect: [][]string{
+				{"19191", "EDD 1", ""},
+				{"x", "y", "z"},
+			},
+		},
+		{
+			policy: "pad",
+			expect: [][]string{
+				{"asd", "igig", "cxxxncnc"},
+				{"19191", "EDD 1", ""},
+				{"x", "y", "z"},
+			},
+		},
+		{
+			policy: "merge",
+			expect: [][]string{
+				{"asd", "igig", "cxxxncnc extra"},
+				{"19191", "EDD 1", ""},
+				{"x", "y", "z"},
+			},
+		},
+	}
+
+	for _, testdata := range tests {
+		testname := fmt.Sprintf("parse-on-error-%s", testdata.policy)
+		t.Run(testname, func(t *testing.T) {
+			conf := cfg.Config{
+				Separator: cfg.SeparatorTemplates[":default:"],
+				OnError:   testdata.policy,
+			}
+
+			readFd := strings.NewReader(strings.TrimSpace(table))
+			data, err := wrapValidateParser(conf, readFd)
+
+			if testdata.wanterror {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.EqualValues(t, testdata.expect, data.entries)
+			}
+		})
+	}
+}
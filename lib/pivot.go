@@ -0,0 +1,221 @@
+/*
+Copyright © 2025 Thomas von Dein
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package lib
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/tlinden/tablizer/cfg"
+)
+
+/*
+Turn long data into a matrix: one row per unique combination of the
+row columns, one column per unique value of the pivot column and the
+cells containing the aggregation of the matching rows. Cells without
+rows get the --pivot-fill value. See pivotHeader() for the headers.
+*/
+func PivotTable(conf cfg.Config, data *Tabdata) (*Tabdata, bool, error) {
+	if conf.Pivot == "" {
+		return nil, false, nil
+	}
+
+	rowcols, err := PrepareColumnVars(conf.PivotRows, data)
+	if err != nil {
+		return nil, false, err
+	}
+
+	for _, col := range rowcols {
+		if col < 1 || col > len(data.headers) {
+			return nil, false, fmt.Errorf("pivot column %d does not exist", col)
+		}
+	}
+
+	pivotcol, err := pivotColumn(conf.PivotColumn, data)
+	if err != nil {
+		return nil, false, err
+	}
+
+	aggregators, err := prepareAggregators([]cfg.Aggregation{conf.PivotAgg}, data)
+	if err != nil {
+		return nil, false, err
+	}
+
+	aggr := aggregators[0]
+
+	// collect the rows of every cell
+	rowkeys := []string{}
+	rowvalues := map[string][]string{}
+	colkeys := []string{}
+	cells := map[string]map[string][][]string{}
+
+	for _, row := range data.entries {
+		values := make([]string, len(rowcols))
+		for idx, col := range rowcols {
+			values[idx] = cell(row, col-1)
+		}
+
+		rowkey := strings.Join(values, "\x00")
+		colkey := cell(row, pivotcol)
+
+		if _, exists := cells[rowkey]; !exists {
+			rowkeys = append(rowkeys, rowkey)
+			rowvalues[rowkey] = values
+			cells[rowkey] = map[string][][]string{}
+		}
+
+		if !slices.Contains(colkeys, colkey) {
+			colkeys = append(colkeys, colkey)
+		}
+
+		cells[rowkey][colkey] = append(cells[rowkey][colkey], row)
+	}
+
+	newdata := Tabdata{malformed: data.malformed}
+
+	for _, col := range rowcols {
+		newdata.headers = append(newdata.headers, data.headers[col-1])
+		newdata.types = append(newdata.types, data.columnType(col-1))
+	}
+
+	for _, colkey := range colkeys {
+		newdata.headers = append(newdata.headers, pivotHeader(newdata.headers, colkey))
+		newdata.types = append(newdata.types, aggr.resultType())
+	}
+
+	for _, header := range newdata.headers {
+		newdata.maxwidthHeader = max(newdata.maxwidthHeader, len(header))
+	}
+
+	newdata.columns = len(newdata.headers)
+
+	for _, rowkey := range rowkeys {
+		newrow := slices.Clone(rowvalues[rowkey])
+
+		for _, colkey := range colkeys {
+			rows, exists := cells[rowkey][colkey]
+			if !exists {
+				newrow = append(newrow, conf.PivotFill)
+
+				continue
+			}
+
+			value, err := aggr.aggregate(rows)
+			if err != nil {
+				return nil, false, err
+			}
+
+			newrow = append(newrow, value)
+		}
+
+		newdata.entries = append(newdata.entries, newrow)
+	}
+
+	return &newdata, true, nil
+}
+
+// resolve the column whose values become headers, which must be
+// exactly one
+func pivotColumn(spec string, data *Tabdata) (int, error) {
+	columns, err := PrepareColumnVars(spec, data)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(columns) != 1 || columns[0] < 1 || columns[0] > len(data.headers) {
+		return 0, fmt.Errorf("pivot column %s must match exactly one column", spec)
+	}
+
+	return columns[0] - 1, nil
+}
+
+// the header of a pivot column, empty values get a placeholder and
+// headers which already exist a number appended
+func pivotHeader(headers []string, value string) string {
+	header := strings.TrimSpace(value)
+	if header == "" {
+		header = "EMPTY"
+	}
+
+	unique := header
+
+	for count := 2; slices.ContainsFunc(headers, func(head string) bool {
+		return strings.EqualFold(head, unique)
+	}); count++ {
+		unique = fmt.Sprintf("%s_%d", header, count)
+	}
+
+	return unique
+}
+
+/*
+The reverse of a pivot  (also known as melt): every column except the
+--unpivot columns becomes a  row containing the id columns, the header
+as key and the cell as value.
+*/
+func UnpivotTable(conf cfg.Config, data *Tabdata) (*Tabdata, bool, error) {
+	if conf.Unpivot == "" {
+		return nil, false, nil
+	}
+
+	idcols, err := PrepareColumnVars(conf.Unpivot, data)
+	if err != nil {
+		return nil, false, err
+	}
+
+	for _, col := range idcols {
+		if col < 1 || col > len(data.headers) {
+			return nil, false, fmt.Errorf("unpivot column %d does not exist", col)
+		}
+	}
+
+	newdata := Tabdata{malformed: data.malformed}
+
+	for _, col := range idcols {
+		newdata.headers = append(newdata.headers, data.headers[col-1])
+	}
+
+	newdata.headers = append(newdata.headers, "key", "value")
+
+	for _, header := range newdata.headers {
+		newdata.maxwidthHeader = max(newdata.maxwidthHeader, len(header))
+	}
+
+	newdata.columns = len(newdata.headers)
+
+	for _, row := range data.entries {
+		for idx, header := range data.headers {
+			if slices.Contains(idcols, idx+1) {
+				continue
+			}
+
+			newrow := []string{}
+			for _, col := range idcols {
+				newrow = append(newrow, cell(row, col-1))
+			}
+
+			newdata.entries = append(newdata.entries, append(newrow, header, cell(row, idx)))
+		}
+	}
+
+	// the value column contains values of all columns, so re-infer
+	inferTypes(&newdata)
+
+	return &newdata, true, nil
+}
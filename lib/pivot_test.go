@@ -0,0 +1,131 @@
+/*
+Copyright © 2025 Thomas von Dein
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package lib
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tlinden/tablizer/cfg"
+)
+
+func newPivotData() Tabdata {
+	data := Tabdata{
+		headers: []string{"NAMESPACE", "NAME", "STATUS", "RESTARTS"},
+		entries: [][]string{
+			{"kube", "a", "Running", "1"},
+			{"kube", "b", "Error", "5"},
+			{"default", "c", "Running", "2"},
+			{"kube", "d", "Running", "3"},
+		},
+	}
+
+	inferTypes(&data)
+
+	return data
+}
+
+func TestPivotTable(t *testing.T) {
+	var tests = []struct {
+		agg     string
+		fill    string
+		expect  [][]string
+		headers []string
+	}{
+		{
+			"count", "",
+			[][]string{{"kube", "2", "1"}, {"default", "1", ""}},
+			[]string{"NAMESPACE", "Running", "Error"},
+		},
+		{
+			"sum(restarts)", "0",
+			[][]string{{"kube", "4", "5"}, {"default", "2", "0"}},
+			[]string{"NAMESPACE", "Running", "Error"},
+		},
+	}
+
+	for _, testdata := range tests {
+		testname := fmt.Sprintf("pivot-table-%s", testdata.agg)
+
+		t.Run(testname, func(t *testing.T) {
+			data := newPivotData()
+			conf := cfg.Config{Pivot: "namespace:status", RawPivotAgg: testdata.agg, PivotFill: testdata.fill}
+
+			assert.NoError(t, conf.PreparePivot())
+
+			newdata, changed, err := PivotTable(conf, &data)
+			assert.NoError(t, err)
+			assert.True(t, changed)
+
+			assert.EqualValues(t, testdata.headers, newdata.headers)
+			assert.EqualValues(t, testdata.expect, newdata.entries)
+			assert.EqualValues(t, cfg.TypeInt, newdata.types[1])
+		})
+	}
+}
+
+func TestPivotHeaders(t *testing.T) {
+	data := Tabdata{
+		headers: []string{"NAMESPACE", "STATUS"},
+		entries: [][]string{
+			{"kube", "Running"},
+			{"kube", ""},
+			{"default", "namespace"},
+			{"default", "Namespace_2"},
+		},
+	}
+
+	inferTypes(&data)
+
+	conf := cfg.Config{Pivot: "namespace:status"}
+	assert.NoError(t, conf.PreparePivot())
+
+	newdata, changed, err := PivotTable(conf, &data)
+	assert.NoError(t, err)
+	assert.True(t, changed)
+
+	assert.EqualValues(t,
+		[]string{"NAMESPACE", "Running", "EMPTY", "namespace_2", "Namespace_2_2"},
+		newdata.headers)
+}
+
+func TestUnpivotTable(t *testing.T) {
+	data := Tabdata{
+		headers: []string{"NAME", "CPU", "MEM"},
+		entries: [][]string{
+			{"a", "10m", "1Gi"},
+			{"b", "20m", "2Gi"},
+		},
+	}
+
+	conf := cfg.Config{Unpivot: "name"}
+
+	newdata, changed, err := UnpivotTable(conf, &data)
+	assert.NoError(t, err)
+	assert.True(t, changed)
+
+	assert.EqualValues(t, []string{"NAME", "key", "value"}, newdata.headers)
+	assert.EqualValues(t, [][]string{
+		{"a", "CPU", "10m"},
+		{"a", "MEM", "1Gi"},
+		{"b", "CPU", "20m"},
+		{"b", "MEM", "2Gi"},
+	}, newdata.entries)
+	assert.Len(t, newdata.types, 3)
+}
@@ -23,7 +23,6 @@ import (
 	"fmt"
 	"io"
 	"log"
-	"strconv"
 	"strings"
 
 	"github.com/gookit/color"
@@ -34,21 +33,47 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
-func printData(writer io.Writer, conf cfg.Config, data *Tabdata) {
+func printData(writer io.Writer, conf cfg.Config, data *Tabdata) error {
+	if len(data.types) != len(data.headers) {
+		// data didn't go through the parser
+		inferTypes(data)
+	}
+
+	// order by fuzzy rank, if requested
+	rankFuzzy(conf, data)
+
 	//  Sort   the  data  first,  before   headers+entries  are  being
 	// reduced. That way the user can specify any valid column to sort
 	// by, independently if it's being used for display or not.
 	sortTable(conf, data)
 
+	// only show the rows we're interested in
+	uniqueRows(&conf, data)
+
+	// aggregate all rows for the footer, if any, before limiting them
+	if err := calculateFooter(conf, data); err != nil {
+		return err
+	}
+
+	limitRows(conf, data)
+
 	// put one or more columns into clipboard
 	yankColumns(conf, data)
 
+	// mark context rows and highlight matches of patterns restricted
+	// to columns
+	colorizeContext(conf, data)
+	colorizeColumns(conf, data)
+
 	// add numbers to headers and remove those we're not interested in
 	numberizeAndReduceHeaders(conf, data)
 
 	// remove unwanted columns, if any
 	reduceColumns(conf, data)
 
+	// turn rows into columns, if demanded
+	rotateTable(conf, data)
+
 	switch conf.OutputMode {
 	case cfg.Extended:
 		printExtendedData(writer, conf, data)
@@ -69,6 +94,8 @@ func printData(writer io.Writer, conf cfg.Config, data *Tabdata) {
 	default:
 		printASCIIData(writer, conf, data)
 	}
+
+	return nil
 }
 
 func output(writer io.Writer, str string) {
@@ -94,7 +121,7 @@ func printOrgmodeData(writer io.Writer, conf cfg.Config, data *Tabdata) {
 					Settings: tw.Settings{
 						Separators: tw.Separators{
 							ShowHeader:     tw.On,
-							ShowFooter:     tw.Off,
+							ShowFooter:     tw.On,
 							BetweenRows:    tw.Off,
 							BetweenColumns: 0,
 						},
@@ -115,6 +142,12 @@ func printOrgmodeData(writer io.Writer, conf cfg.Config, data *Tabdata) {
 						Alignment: tw.AlignLeft,
 					},
 				},
+				Footer: tw.CellConfig{
+					Formatting: tw.CellFormatting{
+						Alignment:  tw.AlignLeft,
+						AutoFormat: tw.Off,
+					},
+				},
 			},
 		),
 	)
@@ -127,6 +160,10 @@ func printOrgmodeData(writer io.Writer, conf cfg.Config, data *Tabdata) {
 		log.Fatalf("Failed to add data to table renderer: %s", err)
 	}
 
+	if footer := footerRow(data); footer != nil {
+		table.Footer(footer)
+	}
+
 	if err := table.Render(); err != nil {
 		log.Fatalf("Failed to render table: %s", err)
 	}
@@ -182,7 +219,20 @@ func printMarkdownData(writer io.Writer, conf cfg.Config, data *Tabdata) {
 		table.Header(data.headers)
 	}
 
-	if err := table.Bulk(data.entries); err != nil {
+	// markdown doesn't know footers, so it's just the last row, in
+	// bold to distinguish it from the data
+	entries := data.entries
+	if footer := footerRow(data); footer != nil {
+		for idx, value := range footer {
+			if value != "" {
+				footer[idx] = "**" + value + "**"
+			}
+		}
+
+		entries = append(entries[:len(entries):len(entries)], footer)
+	}
+
+	if err := table.Bulk(entries); err != nil {
 		log.Fatalf("Failed to add data to table renderer: %s", err)
 	}
 
@@ -230,6 +280,13 @@ func printASCIIData(writer io.Writer, conf cfg.Config, data *Tabdata) {
 				},
 				Padding: tw.CellPadding{Global: tw.Padding{Right: OFS}},
 			},
+			Footer: tw.CellConfig{
+				Formatting: tw.CellFormatting{
+					AutoFormat: tw.Off,
+					Alignment:  tw.AlignLeft,
+				},
+				Padding: tw.CellPadding{Global: tw.Padding{Right: OFS}},
+			},
 
 			Debug: true,
 		}),
@@ -243,6 +300,10 @@ func printASCIIData(writer io.Writer, conf cfg.Config, data *Tabdata) {
 		log.Fatalf("Failed to add data to table renderer: %s", err)
 	}
 
+	if footer := footerRow(data); footer != nil {
+		table.Footer(footer)
+	}
+
 	if err := table.Render(); err != nil {
 		log.Fatalf("Failed to render table: %s", err)
 	}
@@ -268,6 +329,20 @@ func printExtendedData(writer io.Writer, conf cfg.Config, data *Tabdata) {
 		}
 	}
 
+	// the footer values follow as a separate record
+	if len(data.footerValues) > 0 {
+		width := data.maxwidthHeader
+		for _, footer := range data.footerValues {
+			width = max(width, len(footer.header))
+		}
+
+		format = fmt.Sprintf("%%%ds: %%s\n", width)
+
+		for _, footer := range data.footerValues {
+			out += color.Sprintf(format, footer.header, footer.value)
+		}
+	}
+
 	output(writer, colorizeData(conf, out))
 }
 
@@ -290,6 +365,17 @@ func printShellData(writer io.Writer, data *Tabdata) {
 		}
 	}
 
+	if len(data.footerValues) > 0 {
+		shentries := []string{}
+
+		for _, footer := range data.footerValues {
+			shentries = append(shentries, fmt.Sprintf("%s=\"%s\"",
+				footer.header, footer.value))
+		}
+
+		out += strings.Join(shentries, " ") + "\n"
+	}
+
 	// no colorization here
 	output(writer, out)
 }
@@ -302,19 +388,30 @@ func printJsonData(writer io.Writer, data *Tabdata) {
 			obj := make(map[string]any, len(entry))
 
 			for idx, value := range entry {
-				num, err := strconv.Atoi(value)
-				if err == nil {
-					obj[data.headers[idx]] = num
-				} else {
-					obj[data.headers[idx]] = value
-				}
+				obj[data.headers[idx]] = typedValue(data.columnType(idx), value)
 			}
 
 			objlist[i] = obj
 		}
 	}
 
-	jsonstr, err := json.MarshalIndent(&objlist, "", "  ")
+	// without footer the output stays a plain list of rows
+	var jsondata any = objlist
+
+	if len(data.footerValues) > 0 {
+		footer := make(map[string]any, len(data.footerValues))
+
+		for _, value := range data.footerValues {
+			footer[value.header] = typedValue(value.kind, value.value)
+		}
+
+		jsondata = struct {
+			Entries []map[string]any `json:"entries"`
+			Footer  map[string]any   `json:"footer"`
+		}{objlist, footer}
+	}
+
+	jsonstr, err := json.MarshalIndent(jsondata, "", "  ")
 
 	if err != nil {
 		log.Fatal(err)
@@ -326,6 +423,7 @@ func printJsonData(writer io.Writer, data *Tabdata) {
 func printYamlData(writer io.Writer, data *Tabdata) {
 	type Data struct {
 		Entries []map[string]interface{} `yaml:"entries"`
+		Footer  map[string]interface{}   `yaml:"footer,omitempty"`
 	}
 
 	yamlout := Data{}
@@ -334,23 +432,22 @@ func printYamlData(writer io.Writer, data *Tabdata) {
 		yamldata := map[string]interface{}{}
 
 		for idx, entry := range entry {
-			style := yaml.TaggedStyle
-
-			_, err := strconv.Atoi(entry)
-			if err != nil {
-				style = yaml.DoubleQuotedStyle
-			}
-
 			yamldata[strings.ToLower(data.headers[idx])] =
-				&yaml.Node{
-					Kind:  yaml.ScalarNode,
-					Style: style,
-					Value: entry}
+				yamlNode(data.columnType(idx), entry)
 		}
 
 		yamlout.Entries = append(yamlout.Entries, yamldata)
 	}
 
+	if len(data.footerValues) > 0 {
+		yamlout.Footer = map[string]interface{}{}
+
+		for _, footer := range data.footerValues {
+			yamlout.Footer[strings.ToLower(footer.header)] =
+				yamlNode(footer.kind, footer.value)
+		}
+	}
+
 	yamlstr, err := yaml.Marshal(&yamlout)
 
 	if err != nil {
@@ -360,6 +457,26 @@ func printYamlData(writer io.Writer, data *Tabdata) {
 	output(writer, string(yamlstr))
 }
 
+// a scalar yaml node, quoted unless the value is a number or a
+// boolean written as true or false, other values are kept as is
+func yamlNode(kind int, entry string) *yaml.Node {
+	style := yaml.TaggedStyle
+
+	switch typedValue(kind, entry).(type) {
+	case string:
+		style = yaml.DoubleQuotedStyle
+	case bool:
+		if entry != "true" && entry != "false" {
+			style = yaml.DoubleQuotedStyle
+		}
+	}
+
+	return &yaml.Node{
+		Kind:  yaml.ScalarNode,
+		Style: style,
+		Value: entry}
+}
+
 func printCSVData(writer io.Writer, conf cfg.Config, data *Tabdata) {
 	OFS := ","
 	if conf.OFS != "" {
@@ -379,6 +496,12 @@ func printCSVData(writer io.Writer, conf cfg.Config, data *Tabdata) {
 		}
 	}
 
+	if footer := footerRow(data); footer != nil {
+		if err := csvout.Write(footer); err != nil {
+			log.Fatalln("error writing record to csv:", err)
+		}
+	}
+
 	csvout.Flush()
 
 	if err := csvout.Error(); err != nil {
@@ -25,6 +25,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/tlinden/tablizer/cfg"
+	"gopkg.in/yaml.v3"
 )
 
 func newData() Tabdata {
@@ -330,7 +331,7 @@ func TestPrinter(t *testing.T) {
 			data := newData()
 			exp := strings.TrimSpace(testdata.expect)
 
-			printData(&writer, conf, &data)
+			assert.NoError(t, printData(&writer, conf, &data))
 
 			got := strings.TrimSpace(writer.String())
 
@@ -338,3 +339,27 @@ func TestPrinter(t *testing.T) {
 		})
 	}
 }
+
+func TestYamlNode(t *testing.T) {
+	var tests = []struct {
+		kind   int
+		value  string
+		expect yaml.Style
+	}{
+		{cfg.TypeInt, "10", yaml.TaggedStyle},
+		{cfg.TypeBool, "true", yaml.TaggedStyle},
+		{cfg.TypeBool, "yes", yaml.DoubleQuotedStyle},
+		{cfg.TypeString, "10", yaml.DoubleQuotedStyle},
+	}
+
+	for _, testdata := range tests {
+		testname := fmt.Sprintf("yaml-node-%s-%s", cfg.TypeName(testdata.kind), testdata.value)
+
+		t.Run(testname, func(t *testing.T) {
+			node := yamlNode(testdata.kind, testdata.value)
+
+			assert.EqualValues(t, testdata.value, node.Value)
+			assert.EqualValues(t, testdata.expect, node.Style)
+		})
+	}
+}
@@ -19,11 +19,10 @@ package lib
 
 import (
 	"cmp"
-	"regexp"
 	"sort"
 	"strconv"
+	"strings"
 
-	"github.com/araddon/dateparse"
 	"github.com/tlinden/tablizer/cfg"
 )
 
@@ -38,14 +37,33 @@ func sortTable(conf cfg.Config, data *Tabdata) {
 		return
 	}
 
-	// actual sorting
-	sort.SliceStable(data.entries, func(i, j int) bool {
+	// determine how to compare the values of each sort column
+	kinds := make([]int, len(conf.UseSortByColumn))
+	for idx, column := range conf.UseSortByColumn {
+		if conf.SortMode == "auto" {
+			kinds[idx] = data.columnType(column - 1)
+		} else {
+			kinds[idx] = sortModeKind(conf.SortMode)
+		}
+	}
+
+	// actual sorting, of  the row indices, so that  the context marks
+	// follow
+	order := make([]int, len(data.entries))
+	for idx := range order {
+		order[idx] = idx
+	}
+
+	sort.SliceStable(order, func(i, j int) bool {
 		// holds the result of a sort of one column
 		comparators := []int{}
 
-		// iterate over all columns to be sorted, conf.SortMode must be identical!
-		for _, column := range conf.UseSortByColumn {
-			comparators = append(comparators, compare(&conf, data.entries[i][column-1], data.entries[j][column-1]))
+		left, right := data.entries[order[i]], data.entries[order[j]]
+
+		// iterate over all columns to be sorted
+		for idx, column := range conf.UseSortByColumn {
+			comparators = append(comparators,
+				compare(&conf, kinds[idx], left[column-1], right[column-1]))
 		}
 
 		// return the combined result
@@ -59,38 +77,27 @@ func sortTable(conf cfg.Config, data *Tabdata) {
 		}
 
 	})
-}
 
-// config is not modified here, but it would be inefficient to copy it every loop
-func compare(conf *cfg.Config, left string, right string) int {
-	var comp bool
+	data.reorderRows(order)
+}
 
-	switch conf.SortMode {
+// map explicit sort modes to column types
+func sortModeKind(mode string) int {
+	switch mode {
 	case "numeric":
-		left, err := strconv.Atoi(left)
-		if err != nil {
-			left = 0
-		}
-
-		right, err := strconv.Atoi(right)
-		if err != nil {
-			right = 0
-		}
-
-		comp = left < right
+		return cfg.TypeFloat
 	case "duration":
-		left := duration2int(left)
-		right := duration2int(right)
-
-		comp = left < right
+		return cfg.TypeDuration
 	case "time":
-		left, _ := dateparse.ParseAny(left)
-		right, _ := dateparse.ParseAny(right)
-
-		comp = left.Unix() < right.Unix()
+		return cfg.TypeTime
 	default:
-		comp = left < right
+		return cfg.TypeString
 	}
+}
+
+// config is not modified here, but it would be inefficient to copy it every loop
+func compare(conf *cfg.Config, kind int, left string, right string) int {
+	comp := compareValues(kind, left, right) < 0
 
 	if conf.SortDescending {
 		comp = !comp
@@ -112,29 +119,40 @@ the job,  but it's  just another dependency,  just for  this little
 gem. And  we don't need a  time.Time value. And int  is good enough
 for duration comparison.
 
-Convert a  duration into  an integer.  Valid  time units  are "s",
-"m", "h" and "d".
+Convert a  duration into  an integer.  Valid  time units  are "ms",
+"s", "m", "h" and "d", values may be fractional like "1.5h" and
+negative like "-1d2h".
 */
 func duration2int(duration string) int {
-	re := regexp.MustCompile(`(\d+)([dhms])`)
-	seconds := 0
+	return int(duration2float(duration))
+}
+
+// same as duration2int() but keeps fractions of seconds
+func duration2float(duration string) float64 {
+	seconds := 0.0
 
-	for _, match := range re.FindAllStringSubmatch(duration, -1) {
+	for _, match := range durationPartRe.FindAllStringSubmatch(duration, -1) {
 		if len(match) == 3 {
-			durationvalue, _ := strconv.Atoi(match[1])
+			durationvalue, _ := strconv.ParseFloat(match[1], 64)
 
-			switch match[2][0] {
-			case 'd':
+			switch match[2] {
+			case "d":
 				seconds += durationvalue * 86400
-			case 'h':
+			case "h":
 				seconds += durationvalue * 3600
-			case 'm':
+			case "m":
 				seconds += durationvalue * 60
-			case 's':
+			case "s":
 				seconds += durationvalue
+			case "ms":
+				seconds += durationvalue / 1000
 			}
 		}
 	}
 
+	if strings.HasPrefix(duration, "-") {
+		return -seconds
+	}
+
 	return seconds
 }
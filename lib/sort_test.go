@@ -34,6 +34,8 @@ func TestDuration2Seconds(t *testing.T) {
 		{"1h", 60 * 60},
 		{"10m", 60 * 10},
 		{"2h4m10s", (60 * 120) + (4 * 60) + 10},
+		{"1.5h", 90 * 60},
+		{"1m500ms", 60},
 		{"88u", 0},
 		{"19t77X what?4s", 4},
 	}
@@ -72,7 +74,7 @@ func TestCompare(t *testing.T) {
 
 		t.Run(testname, func(t *testing.T) {
 			c := cfg.Config{SortMode: testdata.mode, SortDescending: testdata.desc}
-			got := compare(&c, testdata.a, testdata.b)
+			got := compare(&c, sortModeKind(testdata.mode), testdata.a, testdata.b)
 			assert.EqualValues(t, testdata.want, got)
 		})
 	}
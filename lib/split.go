@@ -0,0 +1,130 @@
+/*
+Copyright © 2025 Thomas von Dein
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package lib
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/tlinden/tablizer/cfg"
+)
+
+/*
+Split columns given with --split col=/regexp/name,... into new columns,
+one per capture group of the regexp. The new columns replace the source
+column unless --split-keep has been given, in which case they are
+inserted after it. Cells not matching the regexp result in empty
+cells.
+*/
+func SplitColumns(conf cfg.Config, data *Tabdata) (*Tabdata, bool, error) {
+	if len(conf.Splits) == 0 {
+		return nil, false, nil
+	}
+
+	for _, split := range conf.Splits {
+		newdata, err := splitColumn(conf, split, data)
+		if err != nil {
+			return nil, false, err
+		}
+
+		data = newdata
+	}
+
+	return data, true, nil
+}
+
+func splitColumn(conf cfg.Config, split cfg.Split, data *Tabdata) (*Tabdata, error) {
+	columns, err := PrepareColumnVars(split.Column, data)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(columns) != 1 || columns[0] < 1 || columns[0] > len(data.headers) {
+		return nil, fmt.Errorf("split column %s must match exactly one column", split.Column)
+	}
+
+	col := columns[0] - 1
+	names := splitNames(split, data.headers[col])
+
+	// the columns after the split ones
+	tail := col + 1
+	if conf.SplitKeep {
+		col++
+	}
+
+	newdata := data.CloneEmpty()
+	newdata.headers = slices.Concat(data.headers[:col], names, data.headers[tail:])
+	newdata.columns = len(newdata.headers)
+
+	for _, row := range data.entries {
+		values := make([]string, len(names))
+
+		if match := split.Search.FindStringSubmatch(cell(row, tail-1)); match != nil {
+			copy(values, match[1:])
+		}
+
+		newrow := make([]string, 0, len(newdata.headers))
+		for idx := range col {
+			newrow = append(newrow, cell(row, idx))
+		}
+
+		newrow = append(newrow, values...)
+
+		for idx := tail; idx < len(data.headers); idx++ {
+			newrow = append(newrow, cell(row, idx))
+		}
+
+		newdata.entries = append(newdata.entries, newrow)
+	}
+
+	// keep the types of existing columns, they may have been
+	// overridden, types may not have been inferred for all of them yet
+	types := make([]int, len(data.headers))
+	copy(types, data.types)
+	newdata.types = slices.Concat(types[:col], make([]int, len(names)), types[tail:])
+
+	for idx, name := range names {
+		newdata.maxwidthHeader = max(newdata.maxwidthHeader, len(name))
+
+		if err := reinferType(conf, &newdata, col+idx); err != nil {
+			return nil, err
+		}
+	}
+
+	return &newdata, nil
+}
+
+// determine the headers of the split columns: the given names, the
+// names of the capture groups or the source header with a number
+func splitNames(split cfg.Split, header string) []string {
+	groups := split.Search.SubexpNames()[1:]
+	names := make([]string, len(groups))
+
+	for idx, group := range groups {
+		switch {
+		case idx < len(split.Names) && split.Names[idx] != "":
+			names[idx] = split.Names[idx]
+		case group != "":
+			names[idx] = group
+		default:
+			names[idx] = fmt.Sprintf("%s_%d", header, idx+1)
+		}
+	}
+
+	return names
+}
@@ -0,0 +1,121 @@
+/*
+Copyright © 2025 Thomas von Dein
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package lib
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tlinden/tablizer/cfg"
+)
+
+func newSplitData() Tabdata {
+	data := Tabdata{
+		headers: []string{"NAME", "READY", "RESTARTS"},
+		entries: [][]string{
+			{"alpha", "2/2", "35 (45m ago)"},
+			{"beta", "0/1", "0"},
+		},
+	}
+
+	inferTypes(&data)
+
+	return data
+}
+
+func TestSplitColumns(t *testing.T) {
+	var tests = []struct {
+		column  string
+		search  string
+		names   []string
+		keep    bool
+		headers []string
+		entries [][]string
+	}{
+		{
+			column:  "restarts",
+			search:  `(\d+) \((.*) ago\)`,
+			names:   []string{"count", "last"},
+			headers: []string{"NAME", "READY", "count", "last"},
+			entries: [][]string{{"alpha", "2/2", "35", "45m"}, {"beta", "0/1", "", ""}},
+		},
+		{
+			column:  "2",
+			search:  `(\d+)/(?P<total>\d+)`,
+			headers: []string{"NAME", "READY_1", "total", "RESTARTS"},
+			entries: [][]string{{"alpha", "2", "2", "35 (45m ago)"}, {"beta", "0", "1", "0"}},
+		},
+		{
+			column:  "ready",
+			search:  `(\d+)/\d+`,
+			names:   []string{"ready_num"},
+			keep:    true,
+			headers: []string{"NAME", "READY", "ready_num", "RESTARTS"},
+			entries: [][]string{{"alpha", "2/2", "2", "35 (45m ago)"}, {"beta", "0/1", "0", "0"}},
+		},
+	}
+
+	for _, testdata := range tests {
+		testname := fmt.Sprintf("split-%s-%s-keep-%t", testdata.column, testdata.search, testdata.keep)
+
+		t.Run(testname, func(t *testing.T) {
+			data := newSplitData()
+			conf := cfg.Config{
+				SplitKeep: testdata.keep,
+				Splits: []cfg.Split{{
+					Column: testdata.column,
+					Search: regexp.MustCompile(testdata.search),
+					Names:  testdata.names,
+				}},
+			}
+
+			newdata, changed, err := SplitColumns(conf, &data)
+
+			assert.NoError(t, err)
+			assert.True(t, changed)
+			assert.EqualValues(t, testdata.headers, newdata.headers)
+			assert.EqualValues(t, testdata.entries, newdata.entries)
+			assert.EqualValues(t, len(testdata.headers), len(newdata.types))
+		})
+	}
+}
+
+func TestSplitColumnsTypes(t *testing.T) {
+	data := newSplitData()
+	conf := cfg.Config{Splits: []cfg.Split{{Column: "ready", Search: regexp.MustCompile(`(\d+)/(\d+)`)}}}
+
+	newdata, _, err := SplitColumns(conf, &data)
+
+	assert.NoError(t, err)
+	assert.EqualValues(t, []int{cfg.TypeString, cfg.TypeInt, cfg.TypeInt, cfg.TypeString}, newdata.types)
+
+	conf.Splits[0].Column = "nonexistent"
+	_, _, err = SplitColumns(conf, &data)
+
+	assert.Error(t, err)
+
+	conf.Splits[0].Column = "ready"
+	conf.Types = map[string]int{"ready_2": cfg.TypeString}
+	data.types = data.types[:1]
+	newdata, _, err = SplitColumns(conf, &data)
+
+	assert.NoError(t, err)
+	assert.EqualValues(t, []int{cfg.TypeString, cfg.TypeInt, cfg.TypeString, cfg.TypeString}, newdata.types)
+}
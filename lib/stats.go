@@ -0,0 +1,192 @@
+/*
+Copyright © 2025 Thomas von Dein
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package lib
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/tlinden/tablizer/cfg"
+)
+
+// the columns of the --stats table
+var statsHeaders = []string{
+	"column", "type", "count", "distinct", "nulls", "min", "max",
+	"mean", "median", "p90", "p99", "top",
+}
+
+/*
+Replace the table by statistics about its columns, one row per column
+containing the type,  the number of values, distinct  values and empty
+values, the  smallest and largest  value. Numeric, duration  and size
+columns also  get the mean,  median and percentiles,  string columns
+the most frequent values.
+*/
+func StatsTable(conf cfg.Config, data *Tabdata) (*Tabdata, bool, error) {
+	if !conf.Stats {
+		return nil, false, nil
+	}
+
+	if conf.StatsTop < 0 {
+		return nil, false, fmt.Errorf("invalid number of top values: %d", conf.StatsTop)
+	}
+
+	newdata := Tabdata{
+		headers:   statsHeaders,
+		columns:   len(statsHeaders),
+		malformed: data.malformed,
+	}
+
+	for _, header := range newdata.headers {
+		newdata.maxwidthHeader = max(newdata.maxwidthHeader, len(header))
+	}
+
+	for idx, header := range data.headers {
+		newdata.entries = append(newdata.entries,
+			columnStats(conf, header, data.columnType(idx), columnValues(data, idx)))
+	}
+
+	inferTypes(&newdata)
+
+	return &newdata, true, nil
+}
+
+// calculate the statistics row of one column
+func columnStats(conf cfg.Config, header string, kind int, values []string) []string {
+	row := make([]string, len(statsHeaders))
+	row[0] = header
+	row[1] = cfg.TypeName(kind)
+
+	counts := map[string]int{}
+	distinct := []string{} // in order of appearance
+	nulls := 0
+	notnull := []string{}
+
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+
+		if isNull(value) {
+			nulls++
+
+			continue
+		}
+
+		if _, exists := counts[value]; !exists {
+			distinct = append(distinct, value)
+		}
+
+		counts[value]++
+		notnull = append(notnull, value)
+	}
+
+	row[2] = strconv.Itoa(len(notnull))
+	row[3] = strconv.Itoa(len(distinct))
+	row[4] = strconv.Itoa(nulls)
+
+	if len(notnull) == 0 {
+		return row
+	}
+
+	row[5], row[6] = notnull[0], notnull[0]
+
+	for _, value := range notnull[1:] {
+		if compareValues(kind, value, row[5]) < 0 {
+			row[5] = value
+		}
+
+		if compareValues(kind, value, row[6]) > 0 {
+			row[6] = value
+		}
+	}
+
+	switch kind {
+	case cfg.TypeInt, cfg.TypeFloat, cfg.TypeDuration, cfg.TypeSize:
+		numbers := []float64{}
+
+		for _, value := range notnull {
+			if number, ok := aggregateNumber(kind, value); ok {
+				numbers = append(numbers, number)
+			}
+		}
+
+		if len(numbers) == 0 {
+			return row
+		}
+
+		slices.Sort(numbers)
+
+		// like avg, mean and median of integers may be fractional
+		meankind := kind
+		if kind == cfg.TypeInt {
+			meankind = cfg.TypeFloat
+		}
+
+		sum := 0.0
+		for _, number := range numbers {
+			sum += number
+		}
+
+		row[7] = formatNumber(meankind, sum/float64(len(numbers)))
+		row[8] = formatNumber(meankind, median(numbers))
+		row[9] = formatNumber(kind, percentile(numbers, 90))
+		row[10] = formatNumber(kind, percentile(numbers, 99))
+	case cfg.TypeString:
+		row[11] = topValues(distinct, counts, conf.StatsTop)
+	}
+
+	return row
+}
+
+// the median of sorted numbers
+func median(numbers []float64) float64 {
+	middle := len(numbers) / 2
+
+	if len(numbers)%2 == 0 {
+		return (numbers[middle-1] + numbers[middle]) / 2
+	}
+
+	return numbers[middle]
+}
+
+// the percentile of sorted numbers using the nearest rank method
+func percentile(numbers []float64, percent float64) float64 {
+	rank := int(math.Ceil(percent / 100 * float64(len(numbers))))
+
+	return numbers[max(rank, 1)-1]
+}
+
+// the most frequent values with their count, ties retain the order of
+// appearance
+func topValues(distinct []string, counts map[string]int, top int) string {
+	values := slices.Clone(distinct)
+
+	slices.SortStableFunc(values, func(left, right string) int {
+		return counts[right] - counts[left]
+	})
+
+	result := []string{}
+
+	for _, value := range values[:min(top, len(values))] {
+		result = append(result, fmt.Sprintf("%s (%d)", value, counts[value]))
+	}
+
+	return strings.Join(result, ", ")
+}
@@ -0,0 +1,77 @@
+/*
+Copyright © 2025 Thomas von Dein
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package lib
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tlinden/tablizer/cfg"
+)
+
+func TestStatsTable(t *testing.T) {
+	data := newAggData()
+	conf := cfg.Config{Stats: true, StatsTop: 2}
+
+	newdata, changed, err := StatsTable(conf, &data)
+	assert.NoError(t, err)
+	assert.True(t, changed)
+
+	assert.EqualValues(t, statsHeaders, newdata.headers)
+	assert.EqualValues(t, [][]string{
+		{"NAME", "string", "4", "4", "0", "a", "d", "", "", "", "", "a (1), b (1)"},
+		{"STATUS", "string", "4", "2", "0", "Error", "Running", "", "", "", "", "Running (3), Error (1)"},
+		{"RESTARTS", "int", "3", "3", "1", "1", "10", "4.666666666666667", "3", "10", "10", ""},
+		{"CPU", "float", "4", "4", "0", "0.25", "1.25", "0.6875", "0.625", "1.25", "1.25", ""},
+		{"AGE", "duration", "4", "4", "0", "30m", "2d", "18h22m30s", "12h30m", "2d", "2d", ""},
+		{"MEM", "size", "4", "2", "0", "512Mi", "1Gi", "768Mi", "768Mi", "1Gi", "1Gi", ""},
+	}, newdata.entries)
+
+	assert.EqualValues(t, cfg.TypeInt, newdata.columnType(2))
+	assert.EqualValues(t, cfg.TypeString, newdata.columnType(5))
+
+	_, changed, err = StatsTable(cfg.Config{}, &data)
+	assert.NoError(t, err)
+	assert.False(t, changed)
+
+	_, _, err = StatsTable(cfg.Config{Stats: true, StatsTop: -1}, &data)
+	assert.Error(t, err)
+}
+
+func TestPercentile(t *testing.T) {
+	var tests = []struct {
+		numbers []float64
+		percent float64
+		expect  float64
+	}{
+		{[]float64{5}, 90, 5},
+		{[]float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, 90, 9},
+		{[]float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, 99, 10},
+		{[]float64{1, 2, 3, 4}, 50, 2},
+		{[]float64{1, 2, 3, 4}, 0, 1},
+	}
+
+	for _, testdata := range tests {
+		testname := fmt.Sprintf("percentile-%v-%g", testdata.numbers, testdata.percent)
+
+		t.Run(testname, func(t *testing.T) {
+			assert.EqualValues(t, testdata.expect, percentile(testdata.numbers, testdata.percent))
+		})
+	}
+}
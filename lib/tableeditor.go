@@ -136,7 +136,8 @@ var (
 			HelpLine{"tab", "navigate columns"},
 		},
 		{
-			HelpLine{"s", "sort alpha-numerically"},
+			HelpLine{"s", "sort by column type"},
+			HelpLine{"S", "sort alpha-numerically"},
 			HelpLine{"n", "sort numerically"},
 			HelpLine{"t", "sort by time"},
 			HelpLine{"d", "sort by duration"},
@@ -368,6 +369,9 @@ func (m FilterTable) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.recalculateTable()
 
 			case "s":
+				m.Sort("auto")
+
+			case "S":
 				m.Sort("alphanumeric")
 
 			case "n":
@@ -399,7 +403,8 @@ func (m FilterTable) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 // Add some info to the footer
 func (m *FilterTable) updateFooter() {
 	selected := m.Table.SelectedRows()
-	footer := fmt.Sprintf("selected: %d ", len(selected))
+	footer := fmt.Sprintf("selected: %d type: %s ", len(selected),
+		cfg.TypeName(m.ctx.data.columnType(m.ctx.selectedColumn)))
 
 	if m.Table.GetIsFilterInputFocused() {
 		footer = fmt.Sprintf("/%s %s", m.Table.GetCurrentFilter(), footer)
@@ -505,6 +510,7 @@ func tableEditor(conf *cfg.Config, data *Tabdata) (*Tabdata, error) {
 	filteredtable := m.(FilterTable)
 
 	data.entries = make([][]string, len(filteredtable.Table.SelectedRows()))
+	data.context = nil
 	for pos, row := range m.(FilterTable).Table.SelectedRows() {
 		entry := make([]string, len(data.headers))
 		for idx, field := range data.headers {
@@ -0,0 +1,349 @@
+/*
+Copyright © 2025 Thomas von Dein
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package lib
+
+import (
+	"bytes"
+	"cmp"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/araddon/dateparse"
+	"github.com/tlinden/tablizer/cfg"
+)
+
+// max number of values per column looked at by type inference
+const SampleSize = 100
+
+var (
+	// candidate types in the order they are being checked, the first
+	// one matching all sampled values of a column wins
+	inferOrder = []int{
+		cfg.TypeInt,
+		cfg.TypeFloat,
+		cfg.TypeBool,
+		cfg.TypeDuration,
+		cfg.TypeSize,
+		cfg.TypeIP,
+		cfg.TypeTime,
+	}
+
+	floatRe    = regexp.MustCompile(`^[-+]?(\d+\.?\d*|\.\d+)([eE][-+]?\d+)?$`)
+	durationRe = regexp.MustCompile(`^-?(\d+(\.\d+)?(ms|[dhms]))+$`)
+
+	// one part of a duration, ms must be checked before m
+	durationPartRe = regexp.MustCompile(`(\d+(?:\.\d+)?)(ms|[dhms])`)
+	sizeRe         = regexp.MustCompile(`^(\d+(?:\.\d+)?)\s*([kKMGTPE]?)(i?)([bB]?)$`)
+
+	sizeUnits = map[string]float64{
+		"":  0,
+		"k": 1,
+		"K": 1,
+		"M": 2,
+		"G": 3,
+		"T": 4,
+		"P": 5,
+		"E": 6,
+	}
+
+	// values considered to be empty
+	nullValues = []string{"", "-", "<none>", "<nil>", "null", "n/a"}
+)
+
+// Determine the type of every column by sampling its values. Type
+// overrides given with --types take precedence.
+func InferTypes(conf cfg.Config, data *Tabdata) error {
+	inferTypes(data)
+
+	return applyTypeOverrides(conf, data)
+}
+
+// same thing without overrides, used if we get data without types
+func inferTypes(data *Tabdata) {
+	data.types = make([]int, len(data.headers))
+
+	for idx := range data.headers {
+		data.types[idx] = inferType(columnValues(data, idx))
+	}
+}
+
+// return the type of the given column, string if unknown
+func (data *Tabdata) columnType(idx int) int {
+	if idx < 0 || idx >= len(data.types) {
+		return cfg.TypeString
+	}
+
+	return data.types[idx]
+}
+
+// infer the type of a column again after its values have changed,
+// types given with --types take precedence
+func reinferType(conf cfg.Config, data *Tabdata, col int) error {
+	data.types[col] = inferType(columnValues(data, col))
+
+	return applyTypeOverrides(conf, data)
+}
+
+func applyTypeOverrides(conf cfg.Config, data *Tabdata) error {
+	for column, kind := range conf.Types {
+		columns, err := PrepareColumnVars(column, data)
+		if err != nil {
+			return err
+		}
+
+		for _, col := range columns {
+			if col > 0 && col <= len(data.types) {
+				data.types[col-1] = kind
+			}
+		}
+	}
+
+	return nil
+}
+
+// get all values of a column
+func columnValues(data *Tabdata, idx int) []string {
+	values := make([]string, 0, len(data.entries))
+
+	for _, row := range data.entries {
+		if idx < len(row) {
+			values = append(values, row[idx])
+		}
+	}
+
+	return values
+}
+
+// determine the type of a list of values by looking at an evenly
+// distributed sample of non-empty values
+func inferType(values []string) int {
+	sample := []string{}
+
+	step := 1
+	if len(values) > SampleSize {
+		step = len(values) / SampleSize
+	}
+
+	for idx := 0; idx < len(values) && len(sample) < SampleSize; idx += step {
+		if !isNull(values[idx]) {
+			sample = append(sample, strings.TrimSpace(values[idx]))
+		}
+	}
+
+	if len(sample) == 0 {
+		return cfg.TypeString
+	}
+
+	for _, kind := range inferOrder {
+		matches := true
+
+		for _, value := range sample {
+			if !isType(kind, value) {
+				matches = false
+
+				break
+			}
+		}
+
+		if matches {
+			return kind
+		}
+	}
+
+	return cfg.TypeString
+}
+
+func isNull(value string) bool {
+	value = strings.ToLower(strings.TrimSpace(value))
+
+	for _, null := range nullValues {
+		if value == null {
+			return true
+		}
+	}
+
+	return false
+}
+
+// check if value can be interpreted as the given type
+func isType(kind int, value string) bool {
+	switch kind {
+	case cfg.TypeInt:
+		_, err := strconv.Atoi(value)
+
+		return err == nil
+	case cfg.TypeIP:
+		return net.ParseIP(value) != nil
+	case cfg.TypeString:
+		return true
+	case cfg.TypeTime:
+		// the date parser  is very lenient and  happily accepts stuff
+		// like "2/2" as 2nd of february in year 0, so we exclude that
+		ts, err := dateparse.ParseAny(value)
+
+		return err == nil && ts.Year() > 0
+	default:
+		_, ok := toNumber(kind, value)
+
+		return ok
+	}
+}
+
+// Convert a value of the given type into a number, which can be used
+// to compare or calculate values. Durations are converted to seconds,
+// timestamps to unix time and sizes to bytes.
+func toNumber(kind int, value string) (float64, bool) {
+	value = strings.TrimSpace(value)
+
+	switch kind {
+	case cfg.TypeInt, cfg.TypeFloat:
+		if !floatRe.MatchString(value) {
+			return 0, false
+		}
+
+		number, err := strconv.ParseFloat(value, 64)
+
+		return number, err == nil
+	case cfg.TypeBool:
+		switch strings.ToLower(value) {
+		case "true", "yes":
+			return 1, true
+		case "false", "no":
+			return 0, true
+		}
+	case cfg.TypeDuration:
+		if durationRe.MatchString(value) {
+			return duration2float(value), true
+		}
+	case cfg.TypeTime:
+		ts, err := dateparse.ParseAny(value)
+		if err == nil {
+			return float64(ts.Unix()), true
+		}
+	case cfg.TypeSize:
+		return size2float(value)
+	}
+
+	return 0, false
+}
+
+/*
+Convert a size string into bytes. Units with an "i" (as in Ki, Gi)
+and units without "B" (as in K, G) are binary units as used by
+kubernetes, ls -h or du -h. Units with "B" (as in KB, GB) are
+decimal units.
+*/
+func size2float(size string) (float64, bool) {
+	match := sizeRe.FindStringSubmatch(size)
+	if len(match) != 5 || (match[2] == "" && match[4] == "") {
+		// plain numbers are not sizes
+		return 0, false
+	}
+
+	number, err := strconv.ParseFloat(match[1], 64)
+	if err != nil {
+		return 0, false
+	}
+
+	base := 1024.0
+	if match[3] == "" && match[4] != "" {
+		base = 1000
+	}
+
+	for i := 0.0; i < sizeUnits[match[2]]; i++ {
+		number *= base
+	}
+
+	return number, true
+}
+
+// compare two values of the given type, returns -1, 0 or +1. Values
+// which cannot be converted are considered to be 0.
+func compareValues(kind int, left, right string) int {
+	switch kind {
+	case cfg.TypeString:
+		return strings.Compare(left, right)
+	case cfg.TypeIP:
+		leftip := net.ParseIP(strings.TrimSpace(left))
+		rightip := net.ParseIP(strings.TrimSpace(right))
+
+		if leftip == nil || rightip == nil {
+			return strings.Compare(left, right)
+		}
+
+		return bytes.Compare(leftip.To16(), rightip.To16())
+	case cfg.TypeDuration:
+		// be lenient here, so that "35 (45m ago)" can be sorted as well
+		return cmp.Compare(duration2float(left), duration2float(right))
+	default:
+		leftnum, _ := toNumber(kind, left)
+		rightnum, _ := toNumber(kind, right)
+
+		return cmp.Compare(leftnum, rightnum)
+	}
+}
+
+// compare two values of the given type, returns false if one of the
+// values cannot be converted
+func compareTyped(kind int, left, right string) (int, bool) {
+	switch kind {
+	case cfg.TypeString:
+		return strings.Compare(left, right), true
+	case cfg.TypeIP:
+		leftip := net.ParseIP(strings.TrimSpace(left))
+		rightip := net.ParseIP(strings.TrimSpace(right))
+
+		if leftip == nil || rightip == nil {
+			return 0, false
+		}
+
+		return bytes.Compare(leftip.To16(), rightip.To16()), true
+	default:
+		leftnum, leftok := toNumber(kind, left)
+		rightnum, rightok := toNumber(kind, right)
+
+		if !leftok || !rightok {
+			return 0, false
+		}
+
+		return cmp.Compare(leftnum, rightnum), true
+	}
+}
+
+// convert a value into a native go type according to its column
+// type, used by JSON output
+func typedValue(kind int, value string) any {
+	switch kind {
+	case cfg.TypeInt:
+		if number, err := strconv.Atoi(value); err == nil {
+			return number
+		}
+	case cfg.TypeFloat:
+		if number, ok := toNumber(kind, value); ok {
+			return number
+		}
+	case cfg.TypeBool:
+		if boolean, ok := toNumber(kind, value); ok {
+			return boolean == 1
+		}
+	}
+
+	return value
+}
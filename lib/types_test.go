@@ -0,0 +1,102 @@
+/*
+Copyright © 2025 Thomas von Dein
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+
+package lib
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tlinden/tablizer/cfg"
+)
+
+func TestInferType(t *testing.T) {
+	var tests = []struct {
+		name   string
+		values []string
+		expect int
+	}{
+		{"int", []string{"1", "-20", "", "300"}, cfg.TypeInt},
+		{"float", []string{"1", "0.5", "3.1415"}, cfg.TypeFloat},
+		{"bool", []string{"true", "False", "yes"}, cfg.TypeBool},
+		{"duration", []string{"1d", "4h35m", "54s", "<none>"}, cfg.TypeDuration},
+		{"fractional-duration", []string{"1.5h", "500ms", "2m30.5s"}, cfg.TypeDuration},
+		{"negative-duration", []string{"-1d23h", "45m", "-2h"}, cfg.TypeDuration},
+		{"size", []string{"1G", "512Mi", "100B", "1.5KB"}, cfg.TypeSize},
+		{"ip", []string{"10.0.0.1", "::1", "192.168.1.10"}, cfg.TypeIP},
+		{"time", []string{"3/1/2014", "2013-Feb-03", "2024-11-18T12:00:00+01:00"}, cfg.TypeTime},
+		{"ready", []string{"1/1", "2/2"}, cfg.TypeString},
+		{"string", []string{"alpha", "1", "2"}, cfg.TypeString},
+		{"empty", []string{"", "-"}, cfg.TypeString},
+	}
+
+	for _, testdata := range tests {
+		testname := fmt.Sprintf("infer-type-%s", testdata.name)
+
+		t.Run(testname, func(t *testing.T) {
+			assert.EqualValues(t, testdata.expect, inferType(testdata.values))
+		})
+	}
+}
+
+func TestInferTypesOverride(t *testing.T) {
+	data := Tabdata{
+		headers: []string{"NAME", "COUNT", "AGE"},
+		entries: [][]string{
+			{"alpha", "10", "1h"},
+			{"beta", "20", "2d"},
+		},
+	}
+
+	conf := cfg.Config{Rawtypes: []string{"count=string"}}
+	assert.NoError(t, conf.PrepareTypes())
+	assert.NoError(t, InferTypes(conf, &data))
+
+	assert.EqualValues(t, []int{cfg.TypeString, cfg.TypeString, cfg.TypeDuration}, data.types)
+}
+
+func TestCompareValues(t *testing.T) {
+	var tests = []struct {
+		kind  int
+		left  string
+		right string
+		want  int
+	}{
+		{cfg.TypeInt, "9", "10", -1},
+		{cfg.TypeString, "9", "10", 1},
+		{cfg.TypeFloat, "0.5", "0.25", 1},
+		{cfg.TypeDuration, "1h", "59m", 1},
+		{cfg.TypeDuration, "-1d", "45m", -1},
+		{cfg.TypeDuration, "500ms", "1s", -1},
+		{cfg.TypeSize, "1Gi", "1024Mi", 0},
+		{cfg.TypeSize, "1KB", "1K", -1},
+		{cfg.TypeIP, "10.0.0.2", "10.0.0.10", -1},
+		{cfg.TypeBool, "false", "true", -1},
+		{cfg.TypeTime, "1/1/1970", "12/24/2022", -1},
+	}
+
+	for _, testdata := range tests {
+		testname := fmt.Sprintf("compare-values-%s-%s-%s",
+			cfg.TypeName(testdata.kind), testdata.left, testdata.right)
+
+		t.Run(testname, func(t *testing.T) {
+			got := compareValues(testdata.kind, testdata.left, testdata.right)
+			assert.EqualValues(t, testdata.want, got)
+		})
+	}
+}
@@ -57,7 +57,7 @@ func DISABLED_TestYankColumns(t *testing.T) {
 			data := newData() // defined in printer_test.go, reused here
 
 			var writer bytes.Buffer
-			printData(&writer, conf, &data)
+			assert.NoError(t, printData(&writer, conf, &data))
 
 			got, err := cb.PasteText()
 